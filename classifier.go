@@ -0,0 +1,243 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// StatementCategory is the category of a SQL statement
+type StatementCategory string
+
+const (
+	StatementCategoryDML     StatementCategory = "DML"     // data manipulation, e.g. SELECT, INSERT
+	StatementCategoryDDL     StatementCategory = "DDL"     // data definition, e.g. CREATE, ALTER
+	StatementCategoryDCL     StatementCategory = "DCL"     // data control, e.g. GRANT, REVOKE
+	StatementCategoryTCL     StatementCategory = "TCL"     // transaction control, e.g. BEGIN, COMMIT
+	StatementCategoryUtility StatementCategory = "UTILITY" // everything else, e.g. EXPLAIN, SET, VACUUM
+)
+
+// StatementTypeSelectInto is the statement type of a SELECT that writes its result into a table or a file
+const StatementTypeSelectInto = "SELECT INTO"
+
+var statementCategories = map[string]StatementCategory{
+	"SELECT":                StatementCategoryDML,
+	StatementTypeSelectInto: StatementCategoryDML,
+	"INSERT":                StatementCategoryDML,
+	"UPDATE":                StatementCategoryDML,
+	"DELETE":                StatementCategoryDML,
+	"MERGE":                 StatementCategoryDML,
+	"REPLACE":               StatementCategoryDML,
+	"UPSERT":                StatementCategoryDML,
+	"VALUES":                StatementCategoryDML,
+	"TABLE":                 StatementCategoryDML,
+	"WITH":                  StatementCategoryDML,
+	"CREATE":                StatementCategoryDDL,
+	"ALTER":                 StatementCategoryDDL,
+	"DROP":                  StatementCategoryDDL,
+	"TRUNCATE":              StatementCategoryDDL,
+	"RENAME":                StatementCategoryDDL,
+	"COMMENT":               StatementCategoryDDL,
+	"GRANT":                 StatementCategoryDCL,
+	"REVOKE":                StatementCategoryDCL,
+	"DENY":                  StatementCategoryDCL,
+	"BEGIN":                 StatementCategoryTCL,
+	"START":                 StatementCategoryTCL,
+	"COMMIT":                StatementCategoryTCL,
+	"END":                   StatementCategoryTCL,
+	"ROLLBACK":              StatementCategoryTCL,
+	"SAVEPOINT":             StatementCategoryTCL,
+	"RELEASE":               StatementCategoryTCL,
+}
+
+// statementClassifier classifies the statements of a query from its value tokens.
+// The type and category are those of the first statement, the query is read-only
+// only if every statement is read-only.
+type statementClassifier struct {
+	statementType string
+	readOnly      bool
+	statements    int
+
+	// state of the current statement
+	depth          int
+	verb           string // leading verb, e.g. SELECT
+	pendingCTE     bool   // WITH seen, waiting for the main statement verb
+	explain        bool
+	explainAnalyze bool
+	explainVerb    string // verb of the explained statement
+	writes         bool   // INSERT, UPDATE, DELETE or MERGE anywhere, e.g. in a writable CTE
+	locks          bool   // SELECT ... FOR UPDATE, FOR SHARE or LOCK IN SHARE MODE
+	selectInto     bool
+	pendingInto    bool // INTO seen in a SELECT, waiting for its target
+	prevValue      string
+}
+
+func (c *statementClassifier) classify(token *Token) {
+	if !isValueToken(token) {
+		return
+	}
+	defer func() { c.prevValue = token.Value }()
+
+	if c.pendingInto {
+		c.pendingInto = false
+		// SELECT ... INTO @var or :var assigns variables and doesn't write data
+		if token.Type != BIND_PARAMETER && token.Type != SYSTEM_VARIABLE && !strings.HasPrefix(token.Value, "@") && !strings.HasPrefix(token.Value, ":") {
+			c.selectInto = true
+		}
+	}
+
+	if token.Type == PUNCTUATION {
+		switch token.Value {
+		case "(":
+			c.depth++
+		case ")":
+			c.depth--
+		case ";":
+			if c.depth <= 0 {
+				c.endStatement()
+			}
+		}
+		return
+	}
+
+	if !isClassifierWord(token) {
+		return
+	}
+
+	if c.verb == "" {
+		c.verb = strings.ToUpper(token.Value)
+		switch c.verb {
+		case "WITH":
+			c.pendingCTE = true
+		case "EXPLAIN", "DESCRIBE", "DESC":
+			c.explain = true
+		}
+		return
+	}
+
+	if c.pendingCTE && c.depth == 0 && isStatementVerb(token.Value) {
+		c.verb = strings.ToUpper(token.Value)
+		c.pendingCTE = false
+	}
+
+	if c.explain && c.explainVerb == "" {
+		if strings.EqualFold(token.Value, "ANALYZE") {
+			c.explainAnalyze = true
+		} else if isStatementVerb(token.Value) {
+			c.explainVerb = strings.ToUpper(token.Value)
+		}
+	}
+
+	switch {
+	case isWriteVerb(token):
+		if strings.EqualFold(c.prevValue, "FOR") || strings.EqualFold(c.prevValue, "KEY") {
+			// row locks, e.g. FOR UPDATE or FOR NO KEY UPDATE
+			c.locks = true
+		} else if !strings.EqualFold(c.prevValue, "ON") {
+			// ON UPDATE / ON DELETE are referential actions
+			c.writes = true
+		}
+	case strings.EqualFold(token.Value, "SHARE"):
+		if strings.EqualFold(c.prevValue, "FOR") || strings.EqualFold(c.prevValue, "KEY") || strings.EqualFold(c.prevValue, "IN") {
+			c.locks = true
+		}
+	case token.Type == KEYWORD && strings.EqualFold(token.Value, "INTO"):
+		if c.verb == "SELECT" && !isInsertIntoPrefix(c.prevValue) {
+			c.pendingInto = true
+		}
+	}
+}
+
+// endStatement records the classification of the current statement and resets its state
+func (c *statementClassifier) endStatement() {
+	if c.verb == "" {
+		return
+	}
+	statementType := c.verb
+	switch {
+	case statementType == "DESC":
+		statementType = "DESCRIBE"
+	case statementType == "SELECT" && c.selectInto:
+		statementType = StatementTypeSelectInto
+	}
+
+	var readOnly bool
+	if c.explain {
+		// EXPLAIN ANALYZE executes the statement
+		readOnly = !c.explainAnalyze || (isReadOnlyVerb(c.explainVerb) && c.isReadOnlyStatement())
+	} else {
+		readOnly = isReadOnlyVerb(c.verb) && c.isReadOnlyStatement()
+	}
+
+	if c.statements == 0 {
+		c.statementType = statementType
+		c.readOnly = readOnly
+	} else {
+		c.readOnly = c.readOnly && readOnly
+	}
+	c.statements++
+
+	*c = statementClassifier{
+		statementType: c.statementType,
+		readOnly:      c.readOnly,
+		statements:    c.statements,
+	}
+}
+
+func (c *statementClassifier) isReadOnlyStatement() bool {
+	return !c.writes && !c.locks && !c.selectInto && !c.pendingInto
+}
+
+// apply sets the classification of the query on the statement metadata
+func (c *statementClassifier) apply(statementMetadata *StatementMetadata) {
+	c.endStatement()
+	if c.statements == 0 {
+		return
+	}
+	statementMetadata.StatementType = strings.Clone(c.statementType)
+	statementMetadata.Category = statementCategories[c.statementType]
+	if statementMetadata.Category == "" {
+		statementMetadata.Category = StatementCategoryUtility
+	}
+	statementMetadata.IsReadOnly = c.readOnly
+}
+
+func isClassifierWord(token *Token) bool {
+	switch token.Type {
+	case IDENT, KEYWORD, COMMAND, FUNCTION, CTE_INDICATOR:
+		return true
+	}
+	return false
+}
+
+var statementVerbs = [...]string{"SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "VALUES", "TABLE"}
+
+// isStatementVerb reports whether value starts a DML statement
+func isStatementVerb(value string) bool {
+	for _, verb := range statementVerbs {
+		if strings.EqualFold(value, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func isReadOnlyVerb(verb string) bool {
+	switch verb {
+	case "SELECT", "VALUES", "TABLE", "SHOW", "DESCRIBE", "DESC", "EXPLAIN":
+		return true
+	}
+	return false
+}
+
+func isWriteVerb(token *Token) bool {
+	if token.Type != COMMAND {
+		return false
+	}
+	return strings.EqualFold(token.Value, "INSERT") || strings.EqualFold(token.Value, "UPDATE") ||
+		strings.EqualFold(token.Value, "DELETE") || strings.EqualFold(token.Value, "MERGE")
+}
+
+// isInsertIntoPrefix reports whether an INTO preceded by value belongs to an INSERT or MERGE
+func isInsertIntoPrefix(value string) bool {
+	return strings.EqualFold(value, "INSERT") || strings.EqualFold(value, "MERGE") ||
+		strings.EqualFold(value, "IGNORE") || strings.EqualFold(value, "REPLACE")
+}
@@ -0,0 +1,186 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerClassifyStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		statementType string
+		category      StatementCategory
+		isReadOnly    bool
+		lexerOpts     []lexerOption
+	}{
+		{
+			input:         "SELECT * FROM users WHERE id = 1",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    true,
+		},
+		{
+			input:         "/* comment */ (SELECT 1) UNION (SELECT 2)",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    true,
+		},
+		{
+			input:         "SELECT * FROM users WHERE id = 1 FOR UPDATE",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "SELECT * FROM users WHERE id = 1 FOR NO KEY UPDATE SKIP LOCKED",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "SELECT * FROM users WHERE id = 1 LOCK IN SHARE MODE",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+			lexerOpts:     []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			input:         "SELECT * INTO users_backup FROM users",
+			statementType: StatementTypeSelectInto,
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "SELECT COUNT(*) INTO @total FROM users",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    true,
+			lexerOpts:     []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			input:         "WITH recent AS (SELECT * FROM orders) SELECT * FROM recent",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    true,
+		},
+		{
+			input:         "WITH deleted AS (DELETE FROM orders WHERE created < now() RETURNING *) SELECT * FROM deleted",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "WITH src AS (SELECT * FROM staging) INSERT INTO orders SELECT * FROM src",
+			statementType: "INSERT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "INSERT INTO users (name) VALUES ('a') ON DUPLICATE KEY UPDATE name = 'a'",
+			statementType: "INSERT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "UPDATE users SET name = 'a'",
+			statementType: "UPDATE",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input:         "EXPLAIN SELECT * FROM users",
+			statementType: "EXPLAIN",
+			category:      StatementCategoryUtility,
+			isReadOnly:    true,
+		},
+		{
+			input:         "EXPLAIN DELETE FROM users",
+			statementType: "EXPLAIN",
+			category:      StatementCategoryUtility,
+			isReadOnly:    true,
+		},
+		{
+			input:         "EXPLAIN ANALYZE DELETE FROM users",
+			statementType: "EXPLAIN",
+			category:      StatementCategoryUtility,
+			isReadOnly:    false,
+		},
+		{
+			input:         "EXPLAIN (ANALYZE, BUFFERS) SELECT * FROM users",
+			statementType: "EXPLAIN",
+			category:      StatementCategoryUtility,
+			isReadOnly:    true,
+		},
+		{
+			input:         "CREATE TABLE users (id int, parent_id int REFERENCES users ON DELETE CASCADE)",
+			statementType: "CREATE",
+			category:      StatementCategoryDDL,
+			isReadOnly:    false,
+		},
+		{
+			input:         "TRUNCATE TABLE users",
+			statementType: "TRUNCATE",
+			category:      StatementCategoryDDL,
+			isReadOnly:    false,
+		},
+		{
+			input:         "GRANT SELECT ON users TO reporting",
+			statementType: "GRANT",
+			category:      StatementCategoryDCL,
+			isReadOnly:    false,
+		},
+		{
+			input:         "begin",
+			statementType: "BEGIN",
+			category:      StatementCategoryTCL,
+			isReadOnly:    false,
+		},
+		{
+			input:         "SHOW TABLES",
+			statementType: "SHOW",
+			category:      StatementCategoryUtility,
+			isReadOnly:    true,
+		},
+		{
+			input:         "VACUUM ANALYZE users",
+			statementType: "VACUUM",
+			category:      StatementCategoryUtility,
+			isReadOnly:    false,
+		},
+		{
+			input:         "SELECT 1; SELECT 2;",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    true,
+		},
+		{
+			input:         "SELECT 1; DELETE FROM users",
+			statementType: "SELECT",
+			category:      StatementCategoryDML,
+			isReadOnly:    false,
+		},
+		{
+			input: "",
+		},
+	}
+
+	normalizer := NewNormalizer(WithClassifyStatement(true))
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input, tt.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.statementType, statementMetadata.StatementType)
+			assert.Equal(t, tt.category, statementMetadata.Category)
+			assert.Equal(t, tt.isReadOnly, statementMetadata.IsReadOnly)
+		})
+	}
+}
+
+func TestNormalizerClassifyStatementDisabled(t *testing.T) {
+	_, statementMetadata, err := NewNormalizer().Normalize("SELECT * FROM users")
+	assert.NoError(t, err)
+	assert.Equal(t, "", statementMetadata.StatementType)
+	assert.Equal(t, StatementCategory(""), statementMetadata.Category)
+	assert.False(t, statementMetadata.IsReadOnly)
+}
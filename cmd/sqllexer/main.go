@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/DataDog/go-sqllexer"
+	"github.com/DataDog/go-sqllexer/lint"
 )
 
 // ObfuscatorConfig holds all obfuscator-related CLI flags
@@ -21,19 +22,43 @@ type ObfuscatorConfig struct {
 	ReplacePositionalParameter bool
 	DollarQuotedFunc           bool
 	KeepJsonPath               bool
+	PlaceholderStyle           string
+	MaskingKey                 string
+	SensitiveColumns           string
+	RedactSecrets              bool
+	ScrubCredentials           bool
 }
 
 // NormalizerConfig holds all normalizer-related CLI flags
 type NormalizerConfig struct {
 	CollectComments               bool
+	ParseComments                 bool
 	CollectCommands               bool
 	CollectTables                 bool
 	CollectProcedures             bool
+	CollectHints                  bool
+	CollectIndexes                bool
+	ClassifyStatement             bool
+	CollectScopes                 bool
+	CollectFunctions              bool
+	CollectComplexity             bool
+	CollectLiteralTypes           bool
 	KeepSQLAlias                  bool
 	UppercaseKeywords             bool
 	RemoveSpaceBetweenParentheses bool
 	KeepTrailingSemicolon         bool
 	KeepIdentifierQuotation       bool
+	KeepHints                     bool
+	CollapseRepeatedTuples        bool
+	GroupBindParameters           bool
+	RedactSecrets                 bool
+}
+
+// FormatterConfig holds all formatter-related CLI flags
+type FormatterConfig struct {
+	Indent       string
+	KeywordCase  string
+	MaxLineWidth int
 }
 
 // CLIConfig holds all CLI configuration
@@ -45,10 +70,30 @@ type CLIConfig struct {
 	WithMetadata bool
 	Obfuscator   ObfuscatorConfig
 	Normalizer   NormalizerConfig
+	Formatter    FormatterConfig
 }
 
 // NewObfuscator creates a sqllexer.Obfuscator from the config
-func (c *ObfuscatorConfig) NewObfuscator() *sqllexer.Obfuscator {
+func (c *ObfuscatorConfig) NewObfuscator() (*sqllexer.Obfuscator, error) {
+	var placeholderStyle sqllexer.PlaceholderStyle
+	switch c.PlaceholderStyle {
+	case "question-mark":
+		placeholderStyle = sqllexer.PlaceholderStyleQuestionMark
+	case "numbered":
+		placeholderStyle = sqllexer.PlaceholderStyleNumbered
+	case "typed":
+		placeholderStyle = sqllexer.PlaceholderStyleTyped
+	default:
+		return nil, fmt.Errorf("invalid placeholder style: %s", c.PlaceholderStyle)
+	}
+	var sensitiveColumns []string
+	if c.SensitiveColumns != "" {
+		sensitiveColumns = strings.Split(c.SensitiveColumns, ",")
+	}
+	var secretScanner *sqllexer.SecretScanner
+	if c.RedactSecrets {
+		secretScanner = sqllexer.NewSecretScanner()
+	}
 	return sqllexer.NewObfuscator(
 		sqllexer.WithReplaceDigits(c.ReplaceDigits),
 		sqllexer.WithReplaceBoolean(c.ReplaceBoolean),
@@ -57,24 +102,65 @@ func (c *ObfuscatorConfig) NewObfuscator() *sqllexer.Obfuscator {
 		sqllexer.WithReplacePositionalParameter(c.ReplacePositionalParameter),
 		sqllexer.WithDollarQuotedFunc(c.DollarQuotedFunc),
 		sqllexer.WithKeepJsonPath(c.KeepJsonPath),
-	)
+		sqllexer.WithPlaceholderStyle(placeholderStyle),
+		sqllexer.WithMaskingKey([]byte(c.MaskingKey)),
+		sqllexer.WithSensitiveColumns(sensitiveColumns),
+		sqllexer.WithSecretScanner(secretScanner),
+		sqllexer.WithScrubCredentials(c.ScrubCredentials),
+	), nil
 }
 
 // NewNormalizer creates a sqllexer.Normalizer from the config
 func (c *NormalizerConfig) NewNormalizer() *sqllexer.Normalizer {
+	var secretScanner *sqllexer.SecretScanner
+	if c.RedactSecrets {
+		secretScanner = sqllexer.NewSecretScanner()
+	}
 	return sqllexer.NewNormalizer(
 		sqllexer.WithCollectComments(c.CollectComments),
+		sqllexer.WithParseComments(c.ParseComments),
 		sqllexer.WithCollectCommands(c.CollectCommands),
 		sqllexer.WithCollectTables(c.CollectTables),
 		sqllexer.WithCollectProcedures(c.CollectProcedures),
+		sqllexer.WithCollectHints(c.CollectHints),
+		sqllexer.WithCollectIndexes(c.CollectIndexes),
+		sqllexer.WithClassifyStatement(c.ClassifyStatement),
+		sqllexer.WithCollectScopes(c.CollectScopes),
+		sqllexer.WithCollectFunctions(c.CollectFunctions),
+		sqllexer.WithCollectComplexity(c.CollectComplexity),
+		sqllexer.WithCollectLiteralTypes(c.CollectLiteralTypes),
 		sqllexer.WithKeepSQLAlias(c.KeepSQLAlias),
 		sqllexer.WithUppercaseKeywords(c.UppercaseKeywords),
 		sqllexer.WithRemoveSpaceBetweenParentheses(c.RemoveSpaceBetweenParentheses),
 		sqllexer.WithKeepTrailingSemicolon(c.KeepTrailingSemicolon),
 		sqllexer.WithKeepIdentifierQuotation(c.KeepIdentifierQuotation),
+		sqllexer.WithKeepHints(c.KeepHints),
+		sqllexer.WithCollapseRepeatedTuples(c.CollapseRepeatedTuples),
+		sqllexer.WithGroupBindParameters(c.GroupBindParameters),
+		sqllexer.WithCommentSecretScanner(secretScanner),
 	)
 }
 
+// NewFormatter creates a sqllexer.Formatter from the config
+func (c *FormatterConfig) NewFormatter() (*sqllexer.Formatter, error) {
+	var keywordCase sqllexer.KeywordCase
+	switch c.KeywordCase {
+	case "preserve":
+		keywordCase = sqllexer.KeywordCasePreserve
+	case "upper":
+		keywordCase = sqllexer.KeywordCaseUpper
+	case "lower":
+		keywordCase = sqllexer.KeywordCaseLower
+	default:
+		return nil, fmt.Errorf("invalid keyword case: %s", c.KeywordCase)
+	}
+	return sqllexer.NewFormatter(
+		sqllexer.WithIndent(c.Indent),
+		sqllexer.WithKeywordCase(keywordCase),
+		sqllexer.WithMaxLineWidth(c.MaxLineWidth),
+	), nil
+}
+
 // DBMSType returns the DBMS type for the lexer
 func (c *CLIConfig) DBMSType() sqllexer.DBMSType {
 	return sqllexer.DBMSType(c.DBMS)
@@ -84,7 +170,7 @@ func parseFlags() *CLIConfig {
 	cfg := &CLIConfig{}
 
 	// General options
-	flag.StringVar(&cfg.Mode, "mode", "obfuscate_and_normalize", "Operation mode: obfuscate, normalize, tokenize, obfuscate_and_normalize")
+	flag.StringVar(&cfg.Mode, "mode", "obfuscate_and_normalize", "Operation mode: obfuscate, normalize, tokenize, obfuscate_and_normalize, lint, detect_injection, format, minify, parameterize")
 	flag.StringVar(&cfg.InputFile, "input", "", "Input file (default: stdin)")
 	flag.StringVar(&cfg.OutputFile, "output", "", "Output file (default: stdout)")
 	flag.StringVar(&cfg.DBMS, "dbms", "", "Database type: mssql, postgresql, mysql, oracle, snowflake")
@@ -98,20 +184,43 @@ func parseFlags() *CLIConfig {
 	flag.BoolVar(&cfg.Obfuscator.ReplacePositionalParameter, "replace-positional-parameter", false, "Replace positional parameters ($1, $2, etc.) with placeholders")
 	flag.BoolVar(&cfg.Obfuscator.DollarQuotedFunc, "dollar-quoted-func", false, "Obfuscate content inside $func$...$func$ blocks instead of replacing entirely")
 	flag.BoolVar(&cfg.Obfuscator.KeepJsonPath, "keep-json-path", false, "Keep JSON path expressions unobfuscated")
+	flag.StringVar(&cfg.Obfuscator.MaskingKey, "masking-key", "", "Mask literals with a keyed HMAC of their value instead of placeholders")
+	flag.StringVar(&cfg.Obfuscator.SensitiveColumns, "sensitive-columns", "", "Comma separated column patterns, e.g. password,*ssn*: only obfuscate the literals of these columns")
+	flag.BoolVar(&cfg.Obfuscator.RedactSecrets, "redact-secrets", false, "Redact AWS keys, JWTs, credit card numbers and emails left in literals and comments, including the comments collected by the normalizer")
+	flag.BoolVar(&cfg.Obfuscator.ScrubCredentials, "scrub-credentials", false, "Redact the secrets of credential clauses such as PASSWORD 'x' or IDENTIFIED BY 'x', even when literals are kept")
+	flag.StringVar(&cfg.Obfuscator.PlaceholderStyle, "placeholder-style", "question-mark", "Placeholder style: question-mark, numbered ($1, :1, @P1 per DBMS), typed (?str, ?num, ?date...)")
 
 	// Normalizer options
 	flag.BoolVar(&cfg.Normalizer.CollectComments, "collect-comments", true, "Collect comments as metadata")
+	flag.BoolVar(&cfg.Normalizer.ParseComments, "parse-comments", false, "Parse sqlcommenter/marginalia comments and trace context as metadata")
 	flag.BoolVar(&cfg.Normalizer.CollectCommands, "collect-commands", true, "Collect SQL commands as metadata")
 	flag.BoolVar(&cfg.Normalizer.CollectTables, "collect-tables", true, "Collect table names as metadata")
 	flag.BoolVar(&cfg.Normalizer.CollectProcedures, "collect-procedures", false, "Collect procedure names as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectHints, "collect-hints", false, "Collect optimizer hints as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectIndexes, "collect-indexes", false, "Collect index names as metadata")
+	flag.BoolVar(&cfg.Normalizer.ClassifyStatement, "classify-statement", false, "Classify the statement type, category and read-only access as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectScopes, "collect-scopes", false, "Collect the scope tree of subqueries and CTEs as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectFunctions, "collect-functions", false, "Collect called functions and their kind as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectComplexity, "collect-complexity", false, "Collect query complexity statistics as metadata")
+	flag.BoolVar(&cfg.Normalizer.CollectLiteralTypes, "collect-literal-types", false, "Collect the types of typed string literals as metadata")
 	flag.BoolVar(&cfg.Normalizer.KeepSQLAlias, "keep-sql-alias", false, "Keep SQL aliases (AS clauses)")
 	flag.BoolVar(&cfg.Normalizer.UppercaseKeywords, "uppercase-keywords", false, "Uppercase SQL keywords")
 	flag.BoolVar(&cfg.Normalizer.RemoveSpaceBetweenParentheses, "remove-space-between-parentheses", false, "Remove spaces inside parentheses")
 	flag.BoolVar(&cfg.Normalizer.KeepTrailingSemicolon, "keep-trailing-semicolon", false, "Keep trailing semicolon (useful for PL/SQL)")
 	flag.BoolVar(&cfg.Normalizer.KeepIdentifierQuotation, "keep-identifier-quotation", false, "Keep identifier quotes (backticks, double quotes, brackets)")
+	flag.BoolVar(&cfg.Normalizer.KeepHints, "keep-hints", false, "Keep optimizer hint comments (/*+ ... */) in the normalized SQL")
+	flag.BoolVar(&cfg.Normalizer.CollapseRepeatedTuples, "collapse-repeated-tuples", false, "Collapse repeated VALUES rows and row constructors into one")
+	flag.BoolVar(&cfg.Normalizer.GroupBindParameters, "group-bind-parameters", false, "Group runs of positional and bind parameters like obfuscated values")
+
+	// Formatter options
+	flag.StringVar(&cfg.Formatter.Indent, "indent", "  ", "Indentation of nested clauses (format mode)")
+	flag.StringVar(&cfg.Formatter.KeywordCase, "keyword-case", "preserve", "Keyword case: preserve, upper, lower (format mode)")
+	flag.IntVar(&cfg.Formatter.MaxLineWidth, "max-line-width", 80, "Wrap lists wider than this width, 0 to disable (format mode)")
 
 	flag.Usage = printUsage
 	flag.Parse()
+	// -redact-secrets also redacts the comments collected by the normalizer
+	cfg.Normalizer.RedactSecrets = cfg.Obfuscator.RedactSecrets
 
 	return cfg
 }
@@ -137,6 +246,16 @@ func main() {
 		result, err = tokenize(cfg, input)
 	case "obfuscate_and_normalize":
 		result, err = obfuscateAndNormalize(cfg, input)
+	case "lint":
+		result, err = lintSQL(cfg, input)
+	case "detect_injection":
+		result, err = detectInjection(cfg, input)
+	case "format":
+		result, err = format(cfg, input)
+	case "minify":
+		result = sqllexer.Minify(input, sqllexer.WithDBMS(cfg.DBMSType()))
+	case "parameterize":
+		result, err = parameterize(cfg, input)
 	default:
 		fmt.Fprintf(os.Stderr, "Invalid mode: %s. Use -help for usage information.\n", cfg.Mode)
 		os.Exit(1)
@@ -155,7 +274,10 @@ func main() {
 }
 
 func obfuscate(cfg *CLIConfig, input string) (string, error) {
-	obfuscator := cfg.Obfuscator.NewObfuscator()
+	obfuscator, err := cfg.Obfuscator.NewObfuscator()
+	if err != nil {
+		return "", err
+	}
 	return obfuscator.Obfuscate(input, sqllexer.WithDBMS(cfg.DBMSType())), nil
 }
 
@@ -172,7 +294,10 @@ func normalize(cfg *CLIConfig, input string) (string, error) {
 }
 
 func obfuscateAndNormalize(cfg *CLIConfig, input string) (string, error) {
-	obfuscator := cfg.Obfuscator.NewObfuscator()
+	obfuscator, err := cfg.Obfuscator.NewObfuscator()
+	if err != nil {
+		return "", err
+	}
 	normalizer := cfg.Normalizer.NewNormalizer()
 
 	result, metadata, err := sqllexer.ObfuscateAndNormalize(input, obfuscator, normalizer, sqllexer.WithDBMS(cfg.DBMSType()))
@@ -189,17 +314,36 @@ func tokenize(cfg *CLIConfig, input string) (string, error) {
 	lexer := sqllexer.New(input, sqllexer.WithDBMS(cfg.DBMSType()))
 
 	var result strings.Builder
-	for {
-		token := lexer.Scan()
-		if token.Type == sqllexer.EOF {
-			break
-		}
+	for token := range lexer.All() {
 		result.WriteString(token.Value)
 		result.WriteByte('\n')
 	}
 	return result.String(), nil
 }
 
+func lintSQL(cfg *CLIConfig, input string) (string, error) {
+	linter := lint.New(lint.WithDBMS(cfg.DBMSType()))
+
+	var result strings.Builder
+	for _, finding := range linter.Lint(input) {
+		fmt.Fprintf(&result, "%d:%d: %s: %s: %s\n", finding.Position.Line, finding.Position.Column, finding.Severity, finding.Rule, finding.Message)
+	}
+	return strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+func format(cfg *CLIConfig, input string) (string, error) {
+	formatter, err := cfg.Formatter.NewFormatter()
+	if err != nil {
+		return "", err
+	}
+	return formatter.Format(input, sqllexer.WithDBMS(cfg.DBMSType())), nil
+}
+
+func detectInjection(cfg *CLIConfig, input string) (string, error) {
+	report := sqllexer.DetectInjection(input, sqllexer.WithDBMS(cfg.DBMSType()))
+	return encodeJSON(report)
+}
+
 func readInput(inputFile string) (string, error) {
 	var reader io.Reader
 	if inputFile == "" {
@@ -250,7 +394,24 @@ func formatWithMetadata(sql string, metadata *sqllexer.StatementMetadata) (strin
 		SQL:      sql,
 		Metadata: metadata,
 	}
+	return encodeJSON(output)
+}
+
+// OutputWithParameters is the output of the parameterize mode
+type OutputWithParameters struct {
+	SQL        string               `json:"sql"`
+	Parameters []sqllexer.Parameter `json:"parameters"`
+}
+
+func parameterize(cfg *CLIConfig, input string) (string, error) {
+	sql, parameters := sqllexer.Parameterize(input, sqllexer.WithDBMS(cfg.DBMSType()))
+	return encodeJSON(OutputWithParameters{
+		SQL:        sql,
+		Parameters: parameters,
+	})
+}
 
+func encodeJSON(output any) (string, error) {
 	var buf strings.Builder
 	encoder := json.NewEncoder(&buf)
 	encoder.SetEscapeHTML(false)
@@ -270,7 +431,7 @@ Usage: sqllexer [flags]
 
 General Flags:
   -mode string
-        Operation mode: obfuscate, normalize, tokenize, obfuscate_and_normalize (default "obfuscate_and_normalize")
+        Operation mode: obfuscate, normalize, tokenize, obfuscate_and_normalize, lint, detect_injection, format, minify, parameterize (default "obfuscate_and_normalize")
   -input string
         Input file (default: stdin)
   -output string
@@ -295,16 +456,42 @@ Obfuscator Flags:
         Obfuscate content inside $func$...$func$ blocks instead of replacing entirely (default false)
   -keep-json-path
         Keep JSON path expressions unobfuscated (default false)
+  -masking-key string
+        Mask literals with a keyed HMAC of their value instead of placeholders
+  -sensitive-columns string
+        Comma separated column patterns, e.g. password,*ssn*: only obfuscate the literals of these columns
+  -redact-secrets
+        Redact AWS keys, JWTs, credit card numbers and emails left in literals and comments, including the comments collected by the normalizer (default false)
+  -scrub-credentials
+        Redact the secrets of credential clauses such as PASSWORD 'x' or IDENTIFIED BY 'x', even when literals are kept (default false)
+  -placeholder-style string
+        Placeholder style: question-mark, numbered ($1, :1, @P1 per DBMS), typed (?str, ?num, ?date...) (default "question-mark")
 
 Normalizer Flags:
   -collect-comments
         Collect comments as metadata (default true)
+  -parse-comments
+        Parse sqlcommenter/marginalia comments and trace context as metadata (default false)
   -collect-commands
         Collect SQL commands as metadata (default true)
   -collect-tables
         Collect table names as metadata (default true)
   -collect-procedures
         Collect procedure names as metadata (default false)
+  -collect-hints
+        Collect optimizer hints as metadata (default false)
+  -collect-indexes
+        Collect index names as metadata (default false)
+  -classify-statement
+        Classify the statement type, category and read-only access as metadata (default false)
+  -collect-scopes
+        Collect the scope tree of subqueries and CTEs as metadata (default false)
+  -collect-functions
+        Collect called functions and their kind as metadata (default false)
+  -collect-complexity
+        Collect query complexity statistics as metadata (default false)
+  -collect-literal-types
+        Collect the types of typed string literals as metadata (default false)
   -keep-sql-alias
         Keep SQL aliases (AS clauses) (default false)
   -uppercase-keywords
@@ -315,6 +502,20 @@ Normalizer Flags:
         Keep trailing semicolon (useful for PL/SQL) (default false)
   -keep-identifier-quotation
         Keep identifier quotes (backticks, double quotes, brackets) (default false)
+  -keep-hints
+        Keep optimizer hint comments (/*+ ... */) in the normalized SQL (default false)
+  -collapse-repeated-tuples
+        Collapse repeated VALUES rows and row constructors into one (default false)
+  -group-bind-parameters
+        Group runs of positional and bind parameters like obfuscated values (default false)
+
+Formatter Flags:
+  -indent string
+        Indentation of nested clauses (format mode) (default "  ")
+  -keyword-case string
+        Keyword case: preserve, upper, lower (format mode) (default "preserve")
+  -max-line-width int
+        Wrap lists wider than this width, 0 to disable (format mode) (default 80)
 
 Examples:
   # Obfuscate SQL from stdin
@@ -332,6 +533,21 @@ Examples:
   # Tokenize SQL
   sqllexer -mode tokenize -input query.sql
 
+  # Report SQL anti-patterns
+  sqllexer -mode lint -input query.sql
+
+  # Score SQL injection patterns in a raw query
+  sqllexer -mode detect_injection -input query.sql
+
+  # Pretty-print SQL with uppercase keywords
+  sqllexer -mode format -keyword-case upper -input query.sql
+
+  # Remove comments and whitespace, keeping the query executable
+  sqllexer -mode minify -input query.sql
+
+  # Replace literals with PostgreSQL placeholders and print their values as JSON
+  sqllexer -mode parameterize -dbms postgresql -input query.sql
+
   # Obfuscate with custom options
   sqllexer -replace-digits=false -keep-json-path=true -input query.sql
 
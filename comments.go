@@ -0,0 +1,282 @@
+package sqllexer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// TraceContext holds the W3C trace context propagated in a SQL comment.
+// See https://www.w3.org/TR/trace-context/
+type TraceContext struct {
+	TraceParent string `json:"traceparent"`
+	TraceState  string `json:"tracestate,omitempty"`
+	Version     string `json:"version"`
+	TraceID     string `json:"trace_id"`
+	ParentID    string `json:"parent_id"`
+	TraceFlags  string `json:"trace_flags"`
+	Sampled     bool   `json:"sampled"`
+}
+
+const (
+	traceParentKey = "traceparent"
+	traceStateKey  = "tracestate"
+)
+
+// parseCommentTags parses the key-value pairs of a sqlcommenter comment
+// (e.g. /*controller='users',traceparent='00-...'*/) or a Rails marginalia comment
+// (e.g. /*application:Foo,controller:bar*/).
+// sqlcommenter keys and values are URL-decoded, marginalia values are kept as is.
+// It returns false if the comment is not made entirely of key-value pairs.
+func parseCommentTags(comment string) (map[string]string, bool) {
+	body := trimCommentDelimiters(comment)
+	if body == "" {
+		return nil, false
+	}
+
+	pairs := splitCommentPairs(body)
+	tags := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			return nil, false
+		}
+		key, value, ok := parseSQLCommenterPair(pair)
+		if !ok {
+			key, value, ok = parseMarginaliaPair(pair)
+		}
+		if !ok {
+			return nil, false
+		}
+		tags[key] = value
+	}
+	return tags, true
+}
+
+// trimCommentDelimiters removes the comment markers (/* */, -- or #) and surrounding spaces.
+func trimCommentDelimiters(comment string) string {
+	switch {
+	case strings.HasPrefix(comment, "/*"):
+		comment = strings.TrimSuffix(comment[2:], "*/")
+	case strings.HasPrefix(comment, "--"):
+		comment = comment[2:]
+	case strings.HasPrefix(comment, "#"):
+		comment = comment[1:]
+	}
+	return strings.TrimSpace(comment)
+}
+
+// splitCommentPairs splits a comment body on commas that are not inside single quotes.
+func splitCommentPairs(body string) []string {
+	var pairs []string
+	inQuotes := false
+	escaped := false
+	start := 0
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == '\'':
+			inQuotes = !inQuotes
+		case ch == ',' && !inQuotes:
+			pairs = append(pairs, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(pairs, body[start:])
+}
+
+// parseSQLCommenterPair parses key='value' as specified by https://google.github.io/sqlcommenter/spec/
+func parseSQLCommenterPair(pair string) (string, string, bool) {
+	key, value, found := strings.Cut(pair, "=")
+	if !found {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if key == "" || len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return "", "", false
+	}
+	// meta characters (single quotes) are escaped with a backslash
+	value = strings.ReplaceAll(value[1:len(value)-1], `\'`, `'`)
+
+	decodedKey, err := url.PathUnescape(key)
+	if err != nil {
+		return "", "", false
+	}
+	decodedValue, err := url.PathUnescape(value)
+	if err != nil {
+		return "", "", false
+	}
+	return decodedKey, decodedValue, true
+}
+
+// parseMarginaliaPair parses key:value as emitted by the Rails marginalia gem
+// and the Rails 7 query log tags in their default format.
+func parseMarginaliaPair(pair string) (string, string, bool) {
+	key, value, found := strings.Cut(pair, ":")
+	if !found || key == "" || value == "" {
+		return "", "", false
+	}
+	for _, ch := range key {
+		if !isAlphaNumeric(ch) && ch != '-' && ch != '.' {
+			return "", "", false
+		}
+	}
+	// marginalia values never contain whitespace, this avoids treating
+	// free-form comments such as /* note: do not remove */ as tags
+	if strings.ContainsAny(value, " \t\n\r") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// parseTraceParent parses a W3C traceparent header value: version-traceid-parentid-flags.
+// It returns nil if the value is malformed.
+func parseTraceParent(traceParent string) *TraceContext {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 {
+		return nil
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	// future versions may append fields, version 00 must have exactly four
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return nil
+	}
+	if !isLowerHex(traceID, 32) || strings.Trim(traceID, "0") == "" {
+		return nil
+	}
+	if !isLowerHex(parentID, 16) || strings.Trim(parentID, "0") == "" {
+		return nil
+	}
+	if !isLowerHex(flags, 2) {
+		return nil
+	}
+	return &TraceContext{
+		TraceParent: traceParent,
+		Version:     version,
+		TraceID:     traceID,
+		ParentID:    parentID,
+		TraceFlags:  flags,
+		Sampled:     hexDigitValue(flags[1])&0x1 == 1,
+	}
+}
+
+// isLowerHex reports whether s is exactly n lowercase hexadecimal characters.
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(rune(s[i])) && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func hexDigitValue(ch byte) byte {
+	if ch >= 'a' {
+		return ch - 'a' + 10
+	}
+	return ch - '0'
+}
+
+// isOptimizerHint reports whether a comment is an optimizer hint,
+// e.g. /*+ INDEX(t idx) */ for Oracle and MySQL, /*+ SeqScan(t) */ for pg_hint_plan or --+ for Oracle.
+func isOptimizerHint(comment string) bool {
+	return strings.HasPrefix(comment, "/*+") || strings.HasPrefix(comment, "--+")
+}
+
+// toMultiLineComment rewrites a single line comment as a multiline comment,
+// so it doesn't comment out the rest of the single line normalized SQL.
+func toMultiLineComment(comment string) string {
+	var body string
+	switch {
+	case strings.HasPrefix(comment, "--"):
+		body = comment[2:]
+	case strings.HasPrefix(comment, "#"):
+		body = comment[1:]
+	default:
+		return comment
+	}
+	body = strings.TrimRight(body, " \t\r")
+	if strings.Contains(body, "*/") {
+		// the comment cannot be safely wrapped, drop its content
+		return "/**/"
+	}
+	if !strings.HasPrefix(body, "+") && !strings.HasPrefix(body, " ") {
+		body = " " + body
+	}
+	return "/*" + body + " */"
+}
+
+// obfuscateComment obfuscates the literals inside a comment by lexing its content
+// and passing each token to obfuscate. The comment delimiters are kept as is.
+func obfuscateComment(comment string, obfuscate func(*Token, *LastValueToken), lexerOpts ...lexerOption) string {
+	var prefix, suffix string
+	switch {
+	case strings.HasPrefix(comment, "/*"):
+		prefix = "/*"
+		if strings.HasSuffix(comment, "*/") && len(comment) >= 4 {
+			suffix = "*/"
+		}
+	case strings.HasPrefix(comment, "--"):
+		prefix = "--"
+	case strings.HasPrefix(comment, "#"):
+		prefix = "#"
+	default:
+		return comment
+	}
+	if strings.HasPrefix(comment[len(prefix):], "+") {
+		prefix += "+"
+	}
+	if len(prefix)+len(suffix) > len(comment) {
+		return comment
+	}
+	body := comment[len(prefix) : len(comment)-len(suffix)]
+
+	var obfuscated strings.Builder
+	obfuscated.Grow(len(comment))
+	obfuscated.WriteString(prefix)
+	var lastValueToken *LastValueToken
+	for token := range New(body, lexerOpts...).All() {
+		obfuscate(&token, lastValueToken)
+		obfuscated.WriteString(token.Value)
+		if isValueToken(&token) {
+			lastValueToken = token.getLastValueToken()
+		}
+	}
+	obfuscated.WriteString(suffix)
+	return obfuscated.String()
+}
+
+// collectCommentTags parses a comment token into the CommentTags and TraceContext metadata.
+// Tags already collected from a previous comment take precedence.
+func (m *metadataSet) collectCommentTags(comment string, statementMetadata *StatementMetadata) {
+	tags, ok := parseCommentTags(comment)
+	if !ok {
+		return
+	}
+	for key, value := range tags {
+		if statementMetadata.CommentTags == nil {
+			statementMetadata.CommentTags = make(map[string]string, len(tags))
+		}
+		if _, exists := statementMetadata.CommentTags[key]; exists {
+			continue
+		}
+		statementMetadata.CommentTags[strings.Clone(key)] = strings.Clone(value)
+		m.size += len(key) + len(value)
+	}
+
+	if statementMetadata.TraceContext == nil {
+		if traceParent, ok := statementMetadata.CommentTags[traceParentKey]; ok {
+			statementMetadata.TraceContext = parseTraceParent(traceParent)
+		}
+	}
+	if statementMetadata.TraceContext != nil && statementMetadata.TraceContext.TraceState == "" {
+		statementMetadata.TraceContext.TraceState = statementMetadata.CommentTags[traceStateKey]
+	}
+}
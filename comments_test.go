@@ -0,0 +1,217 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommentTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		expected map[string]string
+		ok       bool
+	}{
+		{
+			name:    "sqlcommenter",
+			comment: "/*controller='users',action='index',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/",
+			expected: map[string]string{
+				"controller":  "users",
+				"action":      "index",
+				"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+			},
+			ok: true,
+		},
+		{
+			name:    "sqlcommenter url encoded",
+			comment: "/* route='%2Fparam%2A%2Fd',framework='spring%20boot',name='O\\'Brien' */",
+			expected: map[string]string{
+				"route":     "/param*/d",
+				"framework": "spring boot",
+				"name":      "O'Brien",
+			},
+			ok: true,
+		},
+		{
+			name:    "sqlcommenter value with comma",
+			comment: "/*tracestate='congo=t61rcWkgMzE,rojo=00f067aa0ba902b7'*/",
+			expected: map[string]string{
+				"tracestate": "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+			},
+			ok: true,
+		},
+		{
+			name:    "marginalia",
+			comment: "/*application:Foo,controller:bar,action:show,line:/app/models/user.rb:12*/",
+			expected: map[string]string{
+				"application": "Foo",
+				"controller":  "bar",
+				"action":      "show",
+				"line":        "/app/models/user.rb:12",
+			},
+			ok: true,
+		},
+		{
+			name:     "single line comment",
+			comment:  "-- application:Foo",
+			expected: map[string]string{"application": "Foo"},
+			ok:       true,
+		},
+		{
+			name:    "free form comment",
+			comment: "/* this is a comment */",
+			ok:      false,
+		},
+		{
+			name:    "free form comment with colon",
+			comment: "/* note: do not remove */",
+			ok:      false,
+		},
+		{
+			name:    "unquoted sqlcommenter value",
+			comment: "/*controller=users*/",
+			ok:      false,
+		},
+		{
+			name:    "invalid escape",
+			comment: "/*controller='%zz'*/",
+			ok:      false,
+		},
+		{
+			name:    "empty comment",
+			comment: "/**/",
+			ok:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCommentTags(tt.comment)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		traceParent string
+		expected    *TraceContext
+	}{
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			expected: &TraceContext{
+				TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+				Version:     "00",
+				TraceID:     "0af7651916cd43dd8448eb211c80319c",
+				ParentID:    "b7ad6b7169203331",
+				TraceFlags:  "01",
+				Sampled:     true,
+			},
+		},
+		{
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+			expected: &TraceContext{
+				TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+				Version:     "00",
+				TraceID:     "0af7651916cd43dd8448eb211c80319c",
+				ParentID:    "b7ad6b7169203331",
+				TraceFlags:  "00",
+				Sampled:     false,
+			},
+		},
+		{
+			// future versions may carry extra fields
+			traceParent: "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-03-extra",
+			expected: &TraceContext{
+				TraceParent: "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-03-extra",
+				Version:     "01",
+				TraceID:     "0af7651916cd43dd8448eb211c80319c",
+				ParentID:    "b7ad6b7169203331",
+				TraceFlags:  "03",
+				Sampled:     true,
+			},
+		},
+		{traceParent: "00-00000000000000000000000000000000-b7ad6b7169203331-01"},
+		{traceParent: "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01"},
+		{traceParent: "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		{traceParent: "00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01"},
+		{traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra"},
+		{traceParent: "00-0af7651916cd43dd8448eb211c80319c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.traceParent, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseTraceParent(tt.traceParent))
+		})
+	}
+}
+
+func TestNormalizerParseComments(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		commentTags  map[string]string
+		traceContext *TraceContext
+	}{
+		{
+			name: "sqlcommenter with trace context",
+			input: `SELECT * FROM users /*controller='users',db_driver='django.db.backends.postgresql',` +
+				`traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01',tracestate='congo%3Dt61rcWkgMzE%2Crojo%3D00f067aa0ba902b7'*/`,
+			commentTags: map[string]string{
+				"controller":  "users",
+				"db_driver":   "django.db.backends.postgresql",
+				"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+				"tracestate":  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+			},
+			traceContext: &TraceContext{
+				TraceParent: "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+				TraceState:  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+				Version:     "00",
+				TraceID:     "5bd66ef5095369c7b0d1f8f4bd33716a",
+				ParentID:    "c532cb4098ac3dd2",
+				TraceFlags:  "01",
+				Sampled:     true,
+			},
+		},
+		{
+			name:  "marginalia and free form comments",
+			input: "/* free form */ SELECT * FROM users /*application:Foo,controller:bar,action:index*/",
+			commentTags: map[string]string{
+				"application": "Foo",
+				"controller":  "bar",
+				"action":      "index",
+			},
+		},
+		{
+			name:  "first comment wins",
+			input: "/*service='a'*/ SELECT 1 /*service='b',env='prod'*/",
+			commentTags: map[string]string{
+				"service": "a",
+				"env":     "prod",
+			},
+		},
+		{
+			name:        "malformed traceparent",
+			input:       "SELECT 1 /*traceparent='not-a-trace'*/",
+			commentTags: map[string]string{"traceparent": "not-a-trace"},
+		},
+		{
+			name:  "no comments",
+			input: "SELECT * FROM users",
+		},
+	}
+
+	normalizer := NewNormalizer(WithParseComments(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.commentTags, statementMetadata.CommentTags)
+			assert.Equal(t, tt.traceContext, statementMetadata.TraceContext)
+			// comments are not collected unless CollectComments is set
+			assert.Equal(t, []string{}, statementMetadata.Comments)
+		})
+	}
+}
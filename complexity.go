@@ -0,0 +1,236 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// Complexity holds structural statistics of a query computed from its tokens
+type Complexity struct {
+	// Joins is the number of explicit JOINs and comma separated tables (implicit joins)
+	Joins int `json:"joins"`
+	// SubqueryDepth is the maximum nesting depth of subqueries and CTE bodies
+	SubqueryDepth int `json:"subquery_depth"`
+	CTEs          int `json:"ctes"`
+	// UnionBranches is the number of queries combined with UNION, e.g. 3 for a UNION b UNION c
+	UnionBranches int `json:"union_branches"`
+	// Predicates is the number of comparisons and predicates (IN, LIKE, BETWEEN, IS, EXISTS)
+	// in WHERE, ON and HAVING clauses
+	Predicates int `json:"predicates"`
+	// InListSizes are the sizes of the IN (...) value lists, before placeholders are grouped
+	InListSizes []int `json:"in_list_sizes,omitempty"`
+	// ObfuscatedLiterals is the number of literals replaced by the obfuscator
+	ObfuscatedLiterals int `json:"obfuscated_literals"`
+	// SelectStar is true when a select list contains * or t.*
+	SelectStar bool `json:"select_star"`
+	// UnboundedSelect is true when a SELECT reads a table without WHERE, LIMIT, TOP or FETCH
+	UnboundedSelect bool `json:"unbounded_select"`
+}
+
+// complexityFrame is a parenthesized expression or a top-level statement
+type complexityFrame struct {
+	first        bool // the next value token is the first one of the frame
+	subquery     bool
+	inPredicate  bool // in a WHERE, ON or HAVING clause
+	inSelectList bool
+	unions       int
+
+	inList    bool // IN (...) list
+	listItems int  // commas read in the IN list
+	listEmpty bool
+
+	// SELECT query block of the frame
+	selecting bool
+	hasFrom   bool
+	bounded   bool
+}
+
+type complexityCollector struct {
+	complexity Complexity
+	frames     []complexityFrame
+}
+
+// collect reads the next value token. inTableList reports whether the token
+// is read in a comma separated table list.
+func (c *complexityCollector) collect(token *Token, lastValueToken *LastValueToken, inTableList bool) {
+	if !isValueToken(token) {
+		return
+	}
+	if len(c.frames) == 0 {
+		c.frames = append(c.frames, complexityFrame{first: true})
+	}
+	frame := &c.frames[len(c.frames)-1]
+	if frame.first {
+		frame.first = false
+		if len(c.frames) > 1 && (token.Type == CTE_INDICATOR || isStatementVerb(token.Value)) {
+			frame.subquery = true
+			frame.inList = false
+			c.complexity.SubqueryDepth = max(c.complexity.SubqueryDepth, c.subqueryDepth())
+		}
+	}
+
+	if token.Type == PUNCTUATION {
+		switch token.Value {
+		case "(":
+			inList := lastValueToken != nil && lastValueToken.Type == KEYWORD && strings.EqualFold(lastValueToken.Value, "IN")
+			c.frames = append(c.frames, complexityFrame{first: true, inPredicate: frame.inPredicate, inList: inList, listEmpty: true})
+			return
+		case ")":
+			if len(c.frames) > 1 {
+				c.pop()
+				return
+			}
+		case ";":
+			c.endStatement()
+			return
+		case ",":
+			if frame.inList {
+				frame.listItems++
+			}
+			if inTableList {
+				c.complexity.Joins++
+			}
+			return
+		}
+	}
+	frame.listEmpty = false
+
+	switch token.Type {
+	case COMMAND:
+		switch strings.ToUpper(token.Value) {
+		case "SELECT":
+			c.endSelect(frame)
+			frame.selecting = true
+			frame.inSelectList = true
+		case "JOIN", "STRAIGHT_JOIN":
+			if lastValueToken == nil || !strings.EqualFold(lastValueToken.Value, "FOR") {
+				// not the scope of a MySQL index hint, e.g. FORCE INDEX FOR JOIN (idx)
+				c.complexity.Joins++
+			}
+		default:
+			frame.inSelectList = false
+		}
+		frame.inPredicate = false
+	case KEYWORD, IDENT:
+		c.collectKeyword(frame, token)
+	case OPERATOR:
+		if frame.inPredicate && isComparisonOperator(token.Value) {
+			c.complexity.Predicates++
+		}
+	case WILDCARD:
+		if frame.inSelectList && lastValueToken != nil && isSelectListStart(lastValueToken) {
+			c.complexity.SelectStar = true
+		}
+	}
+}
+
+func (c *complexityCollector) collectKeyword(frame *complexityFrame, token *Token) {
+	switch strings.ToUpper(token.Value) {
+	case "FROM":
+		frame.hasFrom = frame.hasFrom || frame.selecting
+		frame.inSelectList = false
+	case "WHERE":
+		frame.bounded = true
+		frame.inPredicate = true
+	case "HAVING", "ON":
+		frame.inPredicate = true
+	case "LIMIT", "TOP", "FETCH":
+		frame.bounded = true
+		frame.inPredicate = false
+	case "UNION":
+		frame.unions++
+		frame.inPredicate = false
+	case "GROUP", "ORDER", "EXCEPT", "INTERSECT", "RETURNING", "SET", "VALUES", "WINDOW", "OFFSET":
+		frame.inPredicate = false
+	case "IN", "LIKE", "ILIKE", "BETWEEN", "IS", "EXISTS":
+		if frame.inPredicate && token.Type == KEYWORD {
+			c.complexity.Predicates++
+		}
+	}
+}
+
+// pop closes the innermost frame
+func (c *complexityCollector) pop() {
+	frame := &c.frames[len(c.frames)-1]
+	c.endFrame(frame)
+	if frame.inList && !frame.listEmpty {
+		c.complexity.InListSizes = append(c.complexity.InListSizes, frame.listItems+1)
+	}
+	c.frames = c.frames[:len(c.frames)-1]
+}
+
+func (c *complexityCollector) endStatement() {
+	for len(c.frames) > 1 {
+		c.pop()
+	}
+	if len(c.frames) == 1 {
+		c.endFrame(&c.frames[0])
+	}
+	c.frames = c.frames[:0]
+}
+
+func (c *complexityCollector) endFrame(frame *complexityFrame) {
+	c.endSelect(frame)
+	if frame.unions > 0 {
+		c.complexity.UnionBranches += frame.unions + 1
+	}
+}
+
+// endSelect ends the SELECT query block of the frame
+func (c *complexityCollector) endSelect(frame *complexityFrame) {
+	if frame.selecting && frame.hasFrom && !frame.bounded {
+		c.complexity.UnboundedSelect = true
+	}
+	frame.selecting = false
+	frame.hasFrom = false
+	frame.bounded = false
+}
+
+func (c *complexityCollector) subqueryDepth() int {
+	depth := 0
+	for _, frame := range c.frames {
+		if frame.subquery {
+			depth++
+		}
+	}
+	return depth
+}
+
+// apply sets the complexity of the query on the statement metadata
+func (c *complexityCollector) apply(statementMetadata *StatementMetadata, ctes int) {
+	c.endStatement()
+	complexity := c.complexity
+	complexity.CTEs = ctes
+	statementMetadata.Complexity = &complexity
+}
+
+// isLiteralToken reports whether the token holds a literal value or a parameter
+func isLiteralToken(token *Token) bool {
+	switch token.Type {
+	case STRING, INCOMPLETE_STRING, NUMBER, DOLLAR_QUOTED_STRING, DOLLAR_QUOTED_FUNCTION,
+		POSITIONAL_PARAMETER, BIND_PARAMETER, BOOLEAN, NULL:
+		return true
+	}
+	return false
+}
+
+func isComparisonOperator(operator string) bool {
+	switch operator {
+	case "=", "==", "<>", "!=", "<", ">", "<=", ">=", "<=>", "~", "~*", "!~", "!~*":
+		return true
+	}
+	return false
+}
+
+// isSelectListStart reports whether a * following lastValueToken selects all columns,
+// e.g. SELECT *, SELECT DISTINCT *, SELECT a, * or SELECT t.*
+func isSelectListStart(lastValueToken *LastValueToken) bool {
+	switch lastValueToken.Type {
+	case COMMAND, KEYWORD:
+		return true
+	case PUNCTUATION:
+		return lastValueToken.Value == ","
+	case IDENT, QUOTED_IDENT:
+		return strings.HasSuffix(lastValueToken.Value, ".")
+	}
+	return false
+}
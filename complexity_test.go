@@ -0,0 +1,109 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerCollectComplexity(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Complexity
+	}{
+		{
+			name:  "simple select",
+			input: "SELECT * FROM users WHERE id = 1",
+			expected: Complexity{
+				Predicates: 1,
+				SelectStar: true,
+			},
+		},
+		{
+			name:  "unbounded select",
+			input: "SELECT u.id, o.* FROM users u, payments p JOIN orders o ON o.user_id = u.id",
+			expected: Complexity{
+				Joins:           2,
+				Predicates:      1,
+				SelectStar:      true,
+				UnboundedSelect: true,
+			},
+		},
+		{
+			name:  "index hint scope is not a join",
+			input: "SELECT a.id FROM a FORCE INDEX FOR JOIN (i3) JOIN b ON b.id = a.id LIMIT 1",
+			expected: Complexity{
+				Joins:      1,
+				Predicates: 1,
+			},
+		},
+		{
+			name:     "limit bounds select",
+			input:    "SELECT a * b, COUNT(*) FROM t GROUP BY a LIMIT 10",
+			expected: Complexity{},
+		},
+		{
+			name:  "in lists and predicates",
+			input: "SELECT id FROM t WHERE a IN (1, 2, 3) AND b NOT IN ('x') AND c BETWEEN 1 AND 5 AND d IS NOT NULL AND (e LIKE 'f%' OR g >= 2)",
+			expected: Complexity{
+				Predicates:  6,
+				InListSizes: []int{3, 1},
+			},
+		},
+		{
+			name:  "subqueries",
+			input: "SELECT * FROM a WHERE a.id IN (SELECT b.id FROM b WHERE EXISTS (SELECT 1 FROM c WHERE c.id = b.id))",
+			expected: Complexity{
+				SubqueryDepth: 2,
+				Predicates:    3,
+				SelectStar:    true,
+			},
+		},
+		{
+			name:  "ctes and unions",
+			input: "WITH x AS (SELECT id FROM a WHERE z = 1), y AS (SELECT id FROM b LIMIT 1) SELECT id FROM x UNION ALL SELECT id FROM y UNION SELECT id FROM c WHERE id > 1",
+			expected: Complexity{
+				SubqueryDepth:   1,
+				CTEs:            2,
+				UnionBranches:   3,
+				Predicates:      2,
+				UnboundedSelect: true,
+			},
+		},
+		{
+			name:     "update is not a select",
+			input:    "UPDATE t SET a = 1, b = 2",
+			expected: Complexity{},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectComplexity(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, &tt.expected, statementMetadata.Complexity)
+		})
+	}
+}
+
+func TestObfuscateAndNormalizeComplexity(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithCollectComplexity(true))
+	normalized, statementMetadata, err := ObfuscateAndNormalize("SELECT * FROM t WHERE a IN (1, 2, 3) AND b = 'x' AND c = $1", obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a IN ( ? ) AND b = ? AND c = $1", normalized)
+	assert.Equal(t, &Complexity{
+		Predicates:         3,
+		InListSizes:        []int{3},
+		ObfuscatedLiterals: 4,
+		SelectStar:         true,
+	}, statementMetadata.Complexity)
+}
+
+func TestNormalizerComplexityDisabled(t *testing.T) {
+	_, statementMetadata, err := NewNormalizer().Normalize("SELECT * FROM t")
+	assert.NoError(t, err)
+	assert.Nil(t, statementMetadata.Complexity)
+}
@@ -0,0 +1,153 @@
+package sqllexer
+
+import "strings"
+
+// credentialValueToken is a value token seen by the credential context
+type credentialValueToken struct {
+	Type  TokenType
+	Value string
+}
+
+// credentialContext finds the secrets of credential clauses, which the obfuscator redacts whatever its config:
+//   - COPY ... CREDENTIALS=(AWS_KEY_ID='...' AWS_SECRET_KEY='...') and Redshift CREDENTIALS '...', ACCESS_KEY_ID '...'
+//   - CREATE USER ... PASSWORD 'x', ALTER ROLE ... PASSWORD 'x' and SET PASSWORD = 'x'
+//   - the argument of MySQL PASSWORD('x') and OLD_PASSWORD('x') in any statement, e.g. SELECT PASSWORD('x')
+//   - IDENTIFIED BY 'x' [REPLACE 'old'], including Oracle unquoted passwords
+//   - CREATE SERVER ... OPTIONS (password 'x') and CREATE USER MAPPING ... OPTIONS (password 'x')
+type credentialContext struct {
+	// verb is the first value token of the statement
+	verb string
+	// credentialStatement is true when the statement may hold a credential clause, see isCredentialStatement
+	credentialStatement bool
+	// previous holds the last two value tokens, most recent first
+	previous [2]credentialValueToken
+	// credentialsDepth is the parenthesis depth inside CREDENTIALS=(...), 0 outside
+	credentialsDepth int
+	// identified is true after IDENTIFIED, waiting for BY
+	identified bool
+	// identifiedBy is true when the password follows, after IDENTIFIED [WITH plugin] BY
+	identifiedBy bool
+	// setPassword is true after SET PASSWORD, the password follows =
+	setPassword bool
+	// passwordCall is 1 after the name of PASSWORD() or OLD_PASSWORD() and 2 after its opening parenthesis
+	passwordCall int
+}
+
+// scrub replaces the token with ? when it is the secret of a credential clause, and reports whether it is one
+func (c *credentialContext) scrub(token *Token) bool {
+	if !isValueToken(token) {
+		return false
+	}
+	if token.Type == PUNCTUATION && token.Value == ";" {
+		*c = credentialContext{}
+		return false
+	}
+	if c.verb == "" {
+		c.verb = token.Value
+		c.credentialStatement = isCredentialStatement(c.verb)
+	}
+	secret := c.isPasswordArgument(token)
+	if c.credentialStatement {
+		secret = c.isSecret(token) || secret
+		c.update(token)
+		c.previous = [2]credentialValueToken{{Type: token.Type, Value: token.Value}, c.previous[0]}
+	}
+	if secret {
+		token.Value = StringPlaceholder
+	}
+	return secret
+}
+
+// isSecret reports whether the token is the secret of a credential clause
+func (c *credentialContext) isSecret(token *Token) bool {
+	if c.identifiedBy {
+		if strings.EqualFold(token.Value, "PASSWORD") || strings.EqualFold(token.Value, "VALUES") {
+			// IDENTIFIED BY PASSWORD '*hash', IDENTIFIED BY VALUES 'hash'
+			return false
+		}
+		c.identifiedBy = false
+		return isCredentialValue(token) || token.Type == IDENT || token.Type == QUOTED_IDENT
+	}
+	if !isCredentialValue(token) {
+		return false
+	}
+	if c.credentialsDepth > 0 || isCredentialKey(c.previous[0].Value) {
+		return true
+	}
+	if c.previous[0].Type == OPERATOR && c.previous[0].Value == "=" {
+		// CREDENTIALS = '...', PASSWORD = '...', SET PASSWORD FOR 'bob'@'%' = '...'
+		return isCredentialKey(c.previous[1].Value) || c.setPassword
+	}
+	return false
+}
+
+// isPasswordArgument reports whether the token is the argument of MySQL PASSWORD('x') or OLD_PASSWORD('x'),
+// a secret in any statement, e.g. SET PASSWORD = PASSWORD('x') or INSERT INTO t VALUES (PASSWORD('x'))
+func (c *credentialContext) isPasswordArgument(token *Token) bool {
+	switch {
+	case token.Type == FUNCTION && (strings.EqualFold(token.Value, "PASSWORD") || strings.EqualFold(token.Value, "OLD_PASSWORD")):
+		c.passwordCall = 1
+	case c.passwordCall == 1 && token.Type == PUNCTUATION && token.Value == "(":
+		c.passwordCall = 2
+	case c.passwordCall == 2:
+		c.passwordCall = 0
+		return isCredentialValue(token)
+	default:
+		c.passwordCall = 0
+	}
+	return false
+}
+
+func (c *credentialContext) update(token *Token) {
+	switch {
+	case token.Type == PUNCTUATION && token.Value == "(":
+		if c.credentialsDepth > 0 {
+			c.credentialsDepth++
+		} else if strings.EqualFold(c.previous[0].Value, "CREDENTIALS") ||
+			c.previous[0].Value == "=" && strings.EqualFold(c.previous[1].Value, "CREDENTIALS") {
+			c.credentialsDepth = 1
+		}
+	case token.Type == PUNCTUATION && token.Value == ")":
+		if c.credentialsDepth > 0 {
+			c.credentialsDepth--
+		}
+	case strings.EqualFold(token.Value, "IDENTIFIED"):
+		c.identified = true
+	case strings.EqualFold(token.Value, "BY") && c.identified:
+		c.identified = false
+		c.identifiedBy = true
+	case strings.EqualFold(token.Value, "REPLACE") && strings.EqualFold(c.previous[1].Value, "BY"):
+		// IDENTIFIED BY 'new' REPLACE 'old', the current password follows
+		c.identifiedBy = true
+	case strings.EqualFold(token.Value, "PASSWORD") && strings.EqualFold(c.verb, "SET"):
+		c.setPassword = true
+	}
+}
+
+// isCredentialStatement reports whether the statement starting with the verb may hold a credential clause
+func isCredentialStatement(verb string) bool {
+	for _, statement := range []string{"COPY", "UNLOAD", "CREATE", "ALTER", "SET", "GRANT"} {
+		if strings.EqualFold(verb, statement) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCredentialValue(token *Token) bool {
+	switch token.Type {
+	case STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, NUMBER:
+		return true
+	}
+	return false
+}
+
+// isCredentialKey reports whether the value following the keyword is a secret
+func isCredentialKey(keyword string) bool {
+	switch strings.ToUpper(keyword) {
+	case "CREDENTIALS", "PASSWORD", "ACCESS_KEY_ID", "SECRET_ACCESS_KEY", "SESSION_TOKEN",
+		"AWS_KEY_ID", "AWS_SECRET_KEY", "AWS_TOKEN", "MASTER_KEY", "AZURE_SAS_TOKEN":
+		return true
+	}
+	return false
+}
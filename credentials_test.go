@@ -0,0 +1,176 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateAndNormalizeCredentials(t *testing.T) {
+	tests := []struct {
+		input          string
+		expected       string
+		hasCredentials bool
+		dbms           DBMSType
+	}{
+		{
+			input:          "COPY INTO t FROM 's3://bucket/path' CREDENTIALS=(AWS_KEY_ID='AKIA' AWS_SECRET_KEY='secret') FILE_FORMAT=(TYPE=CSV)",
+			expected:       "COPY INTO t FROM 's3://bucket/path' CREDENTIALS = ( AWS_KEY_ID = ? AWS_SECRET_KEY = ? ) FILE_FORMAT = ( TYPE = CSV )",
+			hasCredentials: true,
+			dbms:           DBMSSnowflake,
+		},
+		{
+			input:          "COPY t FROM 's3://bucket/path' CREDENTIALS 'aws_access_key_id=AKIA;aws_secret_access_key=secret'",
+			expected:       "COPY t FROM 's3://bucket/path' CREDENTIALS ?",
+			hasCredentials: true,
+		},
+		{
+			input:          "COPY t FROM 's3://bucket/path' ACCESS_KEY_ID 'AKIA' SECRET_ACCESS_KEY 'secret' SESSION_TOKEN 'token'",
+			expected:       "COPY t FROM 's3://bucket/path' ACCESS_KEY_ID ? SECRET_ACCESS_KEY ? SESSION_TOKEN ?",
+			hasCredentials: true,
+		},
+		{
+			input:          "CREATE USER bob WITH ENCRYPTED PASSWORD 'hunter2'",
+			expected:       "CREATE USER bob WITH ENCRYPTED PASSWORD ?",
+			hasCredentials: true,
+			dbms:           DBMSPostgres,
+		},
+		{
+			input:          "ALTER ROLE bob PASSWORD 'hunter2' VALID UNTIL '2030-01-01'",
+			expected:       "ALTER ROLE bob PASSWORD ? VALID UNTIL '2030-01-01'",
+			hasCredentials: true,
+			dbms:           DBMSPostgres,
+		},
+		{
+			input:          "CREATE USER 'bob'@'%' IDENTIFIED BY 'hunter2'",
+			expected:       "CREATE USER 'bob' @ '%' IDENTIFIED BY ?",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "ALTER USER bob IDENTIFIED WITH mysql_native_password BY 'hunter2'",
+			expected:       "ALTER USER bob IDENTIFIED WITH mysql_native_password BY ?",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			// Oracle passwords may be unquoted
+			input:          "CREATE USER bob IDENTIFIED BY tiger",
+			expected:       "CREATE USER bob IDENTIFIED BY ?",
+			hasCredentials: true,
+			dbms:           DBMSOracle,
+		},
+		{
+			input:          "SET PASSWORD FOR 'bob'@'%' = 'hunter2'",
+			expected:       "SET PASSWORD FOR 'bob' @ '%' = ?",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "ALTER USER bob IDENTIFIED BY 'hunter2' REPLACE 'hunter1'",
+			expected:       "ALTER USER bob IDENTIFIED BY ? REPLACE ?",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "ALTER USER bob IDENTIFIED BY tiger REPLACE lion",
+			expected:       "ALTER USER bob IDENTIFIED BY ? REPLACE ?",
+			hasCredentials: true,
+			dbms:           DBMSOracle,
+		},
+		{
+			input:          "SET PASSWORD = PASSWORD('hunter2')",
+			expected:       "SET PASSWORD = PASSWORD ( ? )",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "SET PASSWORD FOR 'bob'@'%' = OLD_PASSWORD('hunter2')",
+			expected:       "SET PASSWORD FOR 'bob' @ '%' = OLD_PASSWORD ( ? )",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "CREATE SERVER s FOREIGN DATA WRAPPER postgres_fdw OPTIONS (host 'db', password 'hunter2')",
+			expected:       "CREATE SERVER s FOREIGN DATA WRAPPER postgres_fdw OPTIONS ( host 'db', password ? )",
+			hasCredentials: true,
+			dbms:           DBMSPostgres,
+		},
+		{
+			input:          "CREATE USER MAPPING FOR bob SERVER s OPTIONS (user 'bob', password 'hunter2')",
+			expected:       "CREATE USER MAPPING FOR bob SERVER s OPTIONS ( user 'bob', password ? )",
+			hasCredentials: true,
+			dbms:           DBMSPostgres,
+		},
+		{
+			input:          "SELECT PASSWORD('hunter2')",
+			expected:       "SELECT PASSWORD ( ? )",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			input:          "INSERT INTO users (name, password) VALUES ('bob', PASSWORD('hunter2'))",
+			expected:       "INSERT INTO users ( name, password ) VALUES ( 'bob', PASSWORD ( ? ) )",
+			hasCredentials: true,
+			dbms:           DBMSMySQL,
+		},
+		{
+			// only statements that can hold credential clauses are scrubbed
+			input:    "SELECT * FROM users WHERE password = 'hunter2'",
+			expected: "SELECT * FROM users WHERE password = 'hunter2'",
+		},
+		{
+			input:          "SELECT * FROM users WHERE password = 'hunter2'; CREATE USER bob PASSWORD 'hunter2'",
+			expected:       "SELECT * FROM users WHERE password = 'hunter2'; CREATE USER bob PASSWORD ?",
+			hasCredentials: true,
+		},
+	}
+
+	// literals are kept unless they are compared to an email
+	obfuscator := NewObfuscator(WithSensitiveColumns([]string{"email"}), WithScrubCredentials(true))
+	normalizer := NewNormalizer()
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got, metadata, err := ObfuscateAndNormalize(tt.input, obfuscator, normalizer, WithDBMS(tt.dbms))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.hasCredentials, metadata.HasCredentials)
+		})
+	}
+}
+
+func TestObfuscatorCredentials(t *testing.T) {
+	// credential clauses are redacted whatever the rest of the obfuscator config
+	obfuscator := NewObfuscator(
+		WithSensitiveColumns([]string{"email"}),
+		WithMaskingKey([]byte("secret")),
+		WithScrubCredentials(true),
+	)
+
+	got := obfuscator.Obfuscate("CREATE USER bob IDENTIFIED BY 'hunter2' PASSWORD EXPIRE INTERVAL 90 DAY", WithDBMS(DBMSMySQL))
+	assert.Equal(t, "CREATE USER bob IDENTIFIED BY ? PASSWORD EXPIRE INTERVAL 90 DAY", got)
+
+	got = obfuscator.Obfuscate("SELECT * FROM users WHERE password = PASSWORD('hunter2')", WithDBMS(DBMSMySQL))
+	assert.Equal(t, "SELECT * FROM users WHERE password = PASSWORD(?)", got)
+
+	normalizer := NewNormalizer()
+	got, metadata, err := ObfuscateAndNormalize("COPY t FROM 's3://bucket' CREDENTIALS 'aws_access_key_id=AKIA'", obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, "COPY t FROM 's3://bucket' CREDENTIALS ?", got)
+	assert.True(t, metadata.HasCredentials)
+}
+
+func TestObfuscatorCredentialsDisabled(t *testing.T) {
+	// without WithScrubCredentials, credential clauses follow the obfuscation of literals
+	obfuscator := NewObfuscator(WithSensitiveColumns([]string{"email"}))
+
+	got := obfuscator.Obfuscate("CREATE USER bob IDENTIFIED BY 'hunter2'", WithDBMS(DBMSMySQL))
+	assert.Equal(t, "CREATE USER bob IDENTIFIED BY 'hunter2'", got)
+
+	normalizer := NewNormalizer()
+	got, metadata, err := ObfuscateAndNormalize("CREATE USER bob PASSWORD 'hunter2'", obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, "CREATE USER bob PASSWORD 'hunter2'", got)
+	assert.False(t, metadata.HasCredentials)
+}
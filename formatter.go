@@ -0,0 +1,434 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// KeywordCase is the case the formatter writes keywords in
+type KeywordCase int
+
+const (
+	KeywordCasePreserve KeywordCase = iota
+	KeywordCaseUpper
+	KeywordCaseLower
+)
+
+type formatterConfig struct {
+	// Indent is the string written for each indentation level, two spaces by default
+	Indent string `json:"indent"`
+
+	// KeywordCase specifies the case of keywords, commands and literals such as NULL or TRUE
+	KeywordCase KeywordCase `json:"keyword_case"`
+
+	// MaxLineWidth is the width above which the comma-separated list of a clause (e.g. the columns of a SELECT)
+	// is wrapped with one item per line. 0 disables wrapping.
+	MaxLineWidth int `json:"max_line_width"`
+}
+
+type formatterOption func(*formatterConfig)
+
+func WithIndent(indent string) formatterOption {
+	return func(c *formatterConfig) {
+		c.Indent = indent
+	}
+}
+
+func WithKeywordCase(keywordCase KeywordCase) formatterOption {
+	return func(c *formatterConfig) {
+		c.KeywordCase = keywordCase
+	}
+}
+
+func WithMaxLineWidth(maxLineWidth int) formatterOption {
+	return func(c *formatterConfig) {
+		c.MaxLineWidth = maxLineWidth
+	}
+}
+
+// Formatter prints SQL queries on multiple lines: each clause starts a new line,
+// subqueries and CTEs are indented and long lists are wrapped.
+// Unlike the normalizer, the formatter does not remove comments or change literals.
+type Formatter struct {
+	config *formatterConfig
+}
+
+func NewFormatter(opts ...formatterOption) *Formatter {
+	formatter := &Formatter{
+		config: &formatterConfig{
+			Indent:       "  ",
+			MaxLineWidth: 80,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(formatter.config)
+	}
+
+	return formatter
+}
+
+// formatToken is a non-space token of the formatted input
+type formatToken struct {
+	Token
+	// adjacent is true when the token directly follows the previous token in the input
+	adjacent bool
+}
+
+// formatFrame is the formatting state of a statement or a subquery
+type formatFrame struct {
+	// indent is the indentation level of the clauses of the frame
+	indent int
+	// closeIndent is the indentation level of the parenthesis closing a subquery
+	closeIndent int
+	// parens is the number of open parentheses in the frame that are not subqueries
+	parens int
+	// first is true until a token is written in the frame
+	first bool
+	// wrap is true when the current clause is written with one item per line
+	wrap bool
+	// breakConditions is true when the current clause is written with one AND/OR condition per line
+	breakConditions bool
+	// between is true after a BETWEEN, until its AND
+	between bool
+}
+
+// formatWriter writes tokens and line breaks
+type formatWriter struct {
+	config  *formatterConfig
+	builder strings.Builder
+	// lineIndent is the indentation level of the current line
+	lineIndent int
+	// pendingLines is the number of line breaks to write before the next token
+	pendingLines int
+	previous     *formatToken
+}
+
+// Format returns the input formatted on multiple lines
+func (f *Formatter) Format(input string, lexerOpts ...lexerOption) string {
+	var tokens []formatToken
+	adjacent := false
+	for token := range New(input, lexerOpts...).All() {
+		if token.Type == SPACE {
+			adjacent = false
+			continue
+		}
+		tokens = append(tokens, formatToken{Token: token, adjacent: adjacent})
+		adjacent = true
+	}
+
+	w := &formatWriter{config: f.config}
+	frames := []*formatFrame{{first: true}}
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		frame := frames[len(frames)-1]
+
+		switch {
+		case token.Type == COMMENT:
+			// a single line comment must end its line
+			w.write(token)
+			w.newline(w.lineIndent, 1)
+			continue
+		case token.Type == MULTILINE_COMMENT:
+			w.write(token)
+			continue
+		case token.Type == PUNCTUATION && token.Value == "(":
+			if next := nextFormatToken(tokens, i); next != nil && isSubqueryStart(next) {
+				w.write(token)
+				frames = append(frames, &formatFrame{indent: w.lineIndent + 1, closeIndent: w.lineIndent, first: true})
+				w.newline(w.lineIndent+1, 1)
+				continue
+			}
+			frame.parens++
+			w.write(token)
+		case token.Type == PUNCTUATION && token.Value == ")":
+			if frame.parens == 0 && len(frames) > 1 {
+				frames = frames[:len(frames)-1]
+				w.newline(frame.closeIndent, 1)
+				w.write(token)
+				frame = frames[len(frames)-1]
+				break
+			}
+			frame.parens = max(frame.parens-1, 0)
+			w.write(token)
+		case token.Type == PUNCTUATION && token.Value == ";":
+			w.write(token)
+			// statements are separated by an empty line
+			frames = frames[:1]
+			*frames[0] = formatFrame{first: true}
+			w.newline(0, 2)
+			continue
+		case frame.parens == 0 && clauseLength(tokens, i, frame.first) > 0:
+			n := clauseLength(tokens, i, frame.first)
+			w.newline(frame.indent, 1)
+			for j := i; j < i+n; j++ {
+				w.write(&tokens[j])
+			}
+			keyword := strings.ToUpper(token.Value)
+			frame.breakConditions = keyword == "WHERE" || keyword == "HAVING"
+			frame.between = false
+			frame.wrap = w.config.MaxLineWidth > 0 && isWideClause(tokens, i, n, w.config.MaxLineWidth-frame.indent*len(w.config.Indent))
+			if frame.wrap {
+				w.newline(frame.indent+1, 1)
+			}
+			i += n - 1
+		case token.Type == PUNCTUATION && token.Value == "," && frame.parens == 0 && frame.wrap:
+			w.write(token)
+			w.newline(frame.indent+1, 1)
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "BETWEEN"):
+			frame.between = true
+			w.write(token)
+		case token.Type == KEYWORD && (strings.EqualFold(token.Value, "AND") || strings.EqualFold(token.Value, "OR")):
+			if frame.between && strings.EqualFold(token.Value, "AND") {
+				frame.between = false
+			} else if frame.parens == 0 && frame.breakConditions {
+				w.newline(frame.indent+1, 1)
+			}
+			w.write(token)
+		default:
+			w.write(token)
+		}
+		frame.first = false
+	}
+	return w.builder.String()
+}
+
+// newline breaks the line before the next token, which is indented at indent.
+// Line breaks are not written at the start of the output.
+func (w *formatWriter) newline(indent int, lines int) {
+	if w.builder.Len() > 0 {
+		w.pendingLines = max(w.pendingLines, lines)
+	}
+	w.lineIndent = indent
+}
+
+func (w *formatWriter) write(token *formatToken) {
+	if w.pendingLines > 0 {
+		for range w.pendingLines {
+			w.builder.WriteByte('\n')
+		}
+		w.pendingLines = 0
+		w.builder.WriteString(strings.Repeat(w.config.Indent, w.lineIndent))
+	} else if w.builder.Len() == 0 {
+		w.builder.WriteString(strings.Repeat(w.config.Indent, w.lineIndent))
+	} else if needsSpace(w.previous, token) {
+		w.builder.WriteByte(' ')
+	}
+
+	switch {
+	case w.config.KeywordCase == KeywordCaseUpper && isKeywordToken(token.Type):
+		w.builder.WriteString(strings.ToUpper(token.Value))
+	case w.config.KeywordCase == KeywordCaseLower && isKeywordToken(token.Type):
+		w.builder.WriteString(strings.ToLower(token.Value))
+	default:
+		w.builder.WriteString(strings.TrimRight(token.Value, "\r\n"))
+	}
+	w.previous = token
+}
+
+func isKeywordToken(tokenType TokenType) bool {
+	switch tokenType {
+	case COMMAND, KEYWORD, CTE_INDICATOR, ALIAS_INDICATOR, PROC_INDICATOR, BOOLEAN, NULL:
+		return true
+	}
+	return false
+}
+
+// needsSpace reports whether a space separates previous and token on the same line
+func needsSpace(previous *formatToken, token *formatToken) bool {
+	if previous == nil {
+		return false
+	}
+	switch token.Value {
+	case ",", ";", ")", "]":
+		return false
+	case "(":
+		if previous.Type == FUNCTION {
+			return false
+		}
+		// keep t(a), LEFT(name, 3) and IN(1, 2) as written
+		if token.adjacent && (previous.Type == IDENT || previous.Type == QUOTED_IDENT || previous.Type == KEYWORD) {
+			return false
+		}
+	case "[":
+		if token.adjacent {
+			return false
+		}
+	case "::":
+		return false
+	}
+	switch previous.Value {
+	case "(", "[", "::":
+		return false
+	case ":":
+		// bind variables such as :name
+		return !token.adjacent
+	}
+	// the lexer splits u.* and t.[Col] into "u." and "*"
+	if strings.HasSuffix(previous.Value, ".") && token.adjacent {
+		return false
+	}
+	return true
+}
+
+// nextFormatToken returns the first token following index i that is not a comment, or nil
+func nextFormatToken(tokens []formatToken, i int) *formatToken {
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].Type != COMMENT && tokens[j].Type != MULTILINE_COMMENT {
+			return &tokens[j]
+		}
+	}
+	return nil
+}
+
+func isSubqueryStart(token *formatToken) bool {
+	return token.Type == CTE_INDICATOR || token.Type == COMMAND && strings.EqualFold(token.Value, "SELECT")
+}
+
+// joinModifiers are the keywords that may precede JOIN, e.g. LEFT OUTER JOIN
+var joinModifiers = map[string]bool{
+	"LEFT":    true,
+	"RIGHT":   true,
+	"FULL":    true,
+	"INNER":   true,
+	"OUTER":   true,
+	"CROSS":   true,
+	"NATURAL": true,
+	"SEMI":    true,
+	"ANTI":    true,
+}
+
+// clauseLength returns the number of tokens of the clause keyword starting at index i, e.g. 2 for GROUP BY,
+// or 0 if no clause starts at i. first is true for the first token of a statement or subquery.
+func clauseLength(tokens []formatToken, i int, first bool) int {
+	token := &tokens[i]
+	value := strings.ToUpper(token.Value)
+	var previous string
+	if i > 0 {
+		previous = strings.ToUpper(tokens[i-1].Value)
+	}
+	isNext := func(offset int, values ...string) bool {
+		if i+offset >= len(tokens) {
+			return false
+		}
+		for _, v := range values {
+			if strings.EqualFold(tokens[i+offset].Value, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch token.Type {
+	case CTE_INDICATOR:
+		// WITH (NOLOCK) is a table hint
+		if first && !isNext(1, "(") {
+			return 1
+		}
+	case COMMAND:
+		switch value {
+		case "SELECT":
+			switch previous {
+			case "GRANT", "REVOKE", ",":
+				return 0
+			}
+			if isNext(1, "DISTINCT", "ALL") {
+				return 2
+			}
+			return 1
+		case "INSERT", "UPDATE", "DELETE", "MERGE":
+			switch previous {
+			// ON UPDATE, FOR UPDATE, ON DUPLICATE KEY UPDATE, GRANT INSERT and trigger events
+			case "ON", "FOR", "KEY", "GRANT", "REVOKE", ",", "BEFORE", "AFTER", "OF", "OR", "INSTEAD", "DO", "THEN":
+				return 0
+			}
+			return 1
+		case "JOIN", "STRAIGHT_JOIN":
+			return 1
+		}
+	case KEYWORD, IDENT:
+		switch value {
+		case "FROM":
+			// DELETE FROM and IS DISTINCT FROM
+			if token.Type == KEYWORD && previous != "DELETE" && previous != "DISTINCT" {
+				return 1
+			}
+		case "WHERE", "HAVING", "LIMIT", "OFFSET", "VALUES", "RETURNING", "WINDOW":
+			if token.Type == KEYWORD {
+				return 1
+			}
+		case "SET":
+			if token.Type == KEYWORD && !first {
+				return 1
+			}
+		case "QUALIFY":
+			return 1
+		case "ON":
+			// upserts: ON CONFLICT and ON DUPLICATE KEY UPDATE
+			if isNext(1, "CONFLICT") {
+				return 2
+			}
+			if isNext(1, "DUPLICATE") && isNext(2, "KEY") && isNext(3, "UPDATE") {
+				return 4
+			}
+		case "GROUP", "ORDER":
+			if isNext(1, "BY") {
+				return 2
+			}
+		case "UNION", "INTERSECT", "EXCEPT", "MINUS":
+			if isNext(1, "ALL", "DISTINCT") {
+				return 2
+			}
+			if token.Type == KEYWORD || isNext(1, "SELECT", "(") {
+				return 1
+			}
+		default:
+			if joinModifiers[value] {
+				for n := 1; n <= 3 && i+n < len(tokens); n++ {
+					if isNext(n, "JOIN") {
+						return n + 1
+					}
+					if !joinModifiers[strings.ToUpper(tokens[i+n].Value)] {
+						break
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// isWideClause reports whether the clause starting at index i, with a keyword of n tokens,
+// is a comma-separated list wider than width
+func isWideClause(tokens []formatToken, i int, n int, width int) bool {
+	length := 0
+	commas := 0
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		token := &tokens[j]
+		if j >= i+n && depth == 0 && clauseLength(tokens, j, false) > 0 {
+			break
+		}
+		if token.Type == PUNCTUATION {
+			switch token.Value {
+			case "(":
+				depth++
+			case ")":
+				if depth == 0 {
+					// end of the subquery
+					return commas > 0 && length > width
+				}
+				depth--
+			case ",":
+				if depth == 0 {
+					commas++
+				}
+			case ";":
+				if depth == 0 {
+					return commas > 0 && length > width
+				}
+			}
+		}
+		length += len(token.Value) + 1
+	}
+	return commas > 0 && length > width
+}
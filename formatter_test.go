@@ -0,0 +1,142 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []formatterOption
+		expected string
+	}{
+		{
+			name:  "clauses",
+			input: "SELECT u.id, count(*) FROM users u LEFT OUTER JOIN orders o ON o.user_id = u.id WHERE u.active = true GROUP BY u.id ORDER BY 2 DESC LIMIT 10",
+			expected: `SELECT u.id, count(*)
+FROM users u
+LEFT OUTER JOIN orders o ON o.user_id = u.id
+WHERE u.active = true
+GROUP BY u.id
+ORDER BY 2 DESC
+LIMIT 10`,
+		},
+		{
+			name:  "conditions",
+			input: "SELECT * FROM t WHERE a = 1 AND b BETWEEN 1 AND 5 OR (c = 2 AND d = 3)",
+			expected: `SELECT *
+FROM t
+WHERE a = 1
+  AND b BETWEEN 1 AND 5
+  OR (c = 2 AND d = 3)`,
+		},
+		{
+			name:  "subquery",
+			input: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 100)",
+			expected: `SELECT *
+FROM users
+WHERE id IN (
+  SELECT user_id
+  FROM orders
+  WHERE total > 100
+)`,
+		},
+		{
+			name:  "ctes",
+			input: "WITH a AS (SELECT id FROM x), b AS (SELECT id FROM a) SELECT * FROM b",
+			expected: `WITH a AS (
+  SELECT id
+  FROM x
+), b AS (
+  SELECT id
+  FROM a
+)
+SELECT *
+FROM b`,
+		},
+		{
+			name:  "wrapped columns",
+			input: "SELECT customer_id, first_name, last_name, email_address, phone_number, created_at FROM customers",
+			expected: `SELECT
+  customer_id,
+  first_name,
+  last_name,
+  email_address,
+  phone_number,
+  created_at
+FROM customers`,
+		},
+		{
+			name:  "max line width",
+			input: "SELECT customer_id, first_name FROM customers",
+			opts:  []formatterOption{WithMaxLineWidth(20)},
+			expected: `SELECT
+  customer_id,
+  first_name
+FROM customers`,
+		},
+		{
+			name:  "keyword case and indent",
+			input: "select a from t where b in (select c from u)",
+			opts:  []formatterOption{WithKeywordCase(KeywordCaseUpper), WithIndent("\t")},
+			expected: `SELECT a
+FROM t
+WHERE b IN (
+	SELECT c
+	FROM u
+)`,
+		},
+		{
+			name:  "lower keywords",
+			input: "SELECT A FROM T WHERE B IS NULL",
+			opts:  []formatterOption{WithKeywordCase(KeywordCaseLower)},
+			expected: `select A
+from T
+where B is null`,
+		},
+		{
+			name:  "union and statements",
+			input: "SELECT a FROM x UNION ALL SELECT b FROM y; DELETE FROM t WHERE id = $1",
+			expected: `SELECT a
+FROM x
+UNION ALL
+SELECT b
+FROM y;
+
+DELETE FROM t
+WHERE id = $1`,
+		},
+		{
+			name:  "insert and update",
+			input: "INSERT INTO t(a, b) VALUES (1, 'x') ON CONFLICT (a) DO UPDATE SET b = excluded.b",
+			expected: `INSERT INTO t(a, b)
+VALUES (1, 'x')
+ON CONFLICT (a) DO UPDATE
+SET b = excluded.b`,
+		},
+		{
+			name:  "comments and literals are kept",
+			input: "SELECT u.*, x::int, arr[1] -- columns\nFROM users u /* users */ WHERE name = :name AND s = 'a  b'",
+			expected: `SELECT u.*, x::int, arr[1] -- columns
+FROM users u /* users */
+WHERE name = :name
+  AND s = 'a  b'`,
+		},
+		{
+			name:     "function parentheses",
+			input:    "SELECT LEFT(name, 3), EXTRACT(YEAR FROM d), COUNT(*) OVER (PARTITION BY a ORDER BY b) FROM t",
+			expected: "SELECT LEFT(name, 3), EXTRACT(YEAR FROM d), COUNT(*) OVER (PARTITION BY a ORDER BY b)\nFROM t",
+			opts:     []formatterOption{WithMaxLineWidth(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewFormatter(tt.opts...)
+			assert.Equal(t, tt.expected, formatter.Format(tt.input))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// FunctionKind is the kind of a function call
+type FunctionKind string
+
+const (
+	FunctionKindScalar    FunctionKind = "scalar"    // e.g. lower(name), now()
+	FunctionKindAggregate FunctionKind = "aggregate" // e.g. COUNT(*), string_agg(name, ',')
+	FunctionKindWindow    FunctionKind = "window"    // followed by OVER, e.g. ROW_NUMBER() OVER (...)
+	FunctionKindTable     FunctionKind = "table"     // table-valued, e.g. FROM generate_series(1, 10)
+)
+
+// Function is a function called by a query
+type Function struct {
+	Name string       `json:"name"`
+	Kind FunctionKind `json:"kind"`
+}
+
+var aggregateFunctions = map[string]struct{}{
+	"COUNT": {}, "COUNT_BIG": {}, "SUM": {}, "AVG": {}, "MIN": {}, "MAX": {},
+	"ARRAY_AGG": {}, "STRING_AGG": {}, "GROUP_CONCAT": {}, "LISTAGG": {},
+	"JSON_AGG": {}, "JSONB_AGG": {}, "JSON_OBJECT_AGG": {}, "JSONB_OBJECT_AGG": {},
+	"JSON_ARRAYAGG": {}, "JSON_OBJECTAGG": {}, "XMLAGG": {}, "ARRAY_UNIQUE_AGG": {},
+	"BOOL_AND": {}, "BOOL_OR": {}, "EVERY": {}, "BIT_AND": {}, "BIT_OR": {}, "BIT_XOR": {},
+	"STDDEV": {}, "STDDEV_POP": {}, "STDDEV_SAMP": {}, "STDEV": {}, "STDEVP": {},
+	"VARIANCE": {}, "VAR_POP": {}, "VAR_SAMP": {}, "VAR": {}, "VARP": {},
+	"CORR": {}, "COVAR_POP": {}, "COVAR_SAMP": {}, "REGR_SLOPE": {}, "REGR_INTERCEPT": {},
+	"PERCENTILE_CONT": {}, "PERCENTILE_DISC": {}, "MEDIAN": {}, "MODE": {},
+	"APPROX_COUNT_DISTINCT": {}, "APPROX_PERCENTILE": {}, "ANY_VALUE": {}, "CHECKSUM_AGG": {},
+}
+
+// functionCall is a function call whose closing parenthesis hasn't been read yet
+type functionCall struct {
+	index int // index of the call in functionCollector.calls
+	depth int // parentheses depth inside the call
+}
+
+// functionCollector collects the function calls of a query in order of appearance.
+// A call is classified once its closing parenthesis and any OVER clause have been read.
+type functionCollector struct {
+	calls []Function
+	open  []functionCall
+	depth int
+
+	// closed is the index of the last closed call, waiting for an OVER clause, or -1
+	closed int
+	// suffixDepth is the depth of a FILTER (...) or WITHIN GROUP (...) clause of the closed call, or 0
+	suffixDepth int
+	inSuffix    bool // FILTER or WITHIN read after the closed call
+}
+
+// collect reads the next value token. call reports whether the token is a function call
+// and tableValued whether the function is called in a FROM clause.
+func (c *functionCollector) collect(token *Token, call bool, tableValued bool) {
+	if c.closed >= 0 && c.suffixDepth == 0 {
+		switch {
+		case isFunctionSuffixWord(token, "OVER"):
+			c.calls[c.closed].Kind = FunctionKindWindow
+			c.closed = -1
+		case isFunctionSuffixWord(token, "FILTER") || isFunctionSuffixWord(token, "WITHIN") ||
+			(c.inSuffix && isFunctionSuffixWord(token, "GROUP")):
+			c.inSuffix = true
+		case c.inSuffix && token.Type == PUNCTUATION && token.Value == "(":
+		default:
+			c.closed = -1
+		}
+	}
+
+	if call {
+		kind := FunctionKindScalar
+		if tableValued {
+			kind = FunctionKindTable
+		} else if _, ok := aggregateFunctions[strings.ToUpper(token.Value)]; ok {
+			kind = FunctionKindAggregate
+		}
+		c.calls = append(c.calls, Function{Name: token.Value, Kind: kind})
+		c.open = append(c.open, functionCall{index: len(c.calls) - 1, depth: c.depth + 1})
+		return
+	}
+
+	if token.Type != PUNCTUATION {
+		return
+	}
+	switch token.Value {
+	case "(":
+		c.depth++
+		if c.closed >= 0 && c.inSuffix && c.suffixDepth == 0 {
+			c.suffixDepth = c.depth
+		}
+	case ")":
+		if c.closed >= 0 && c.suffixDepth == c.depth {
+			// end of FILTER (...) or WITHIN GROUP (...), OVER may follow
+			c.suffixDepth = 0
+			c.inSuffix = false
+		} else if n := len(c.open); n > 0 && c.open[n-1].depth == c.depth {
+			if c.suffixDepth == 0 {
+				c.closed = c.open[n-1].index
+				c.inSuffix = false
+			}
+			c.open = c.open[:n-1]
+		}
+		if c.depth > 0 {
+			c.depth--
+		}
+	case ";":
+		c.open = c.open[:0]
+		c.depth = 0
+		c.closed = -1
+		c.suffixDepth = 0
+		c.inSuffix = false
+	}
+}
+
+// isFunctionCall reports whether a FUNCTION token is a function call rather than
+// a table, CTE or routine name followed by a column or parameter list, e.g. INSERT INTO t(a).
+// tableValued reports whether the function is called in a FROM clause.
+func isFunctionCall(lastValueToken *LastValueToken, inTableList bool) (call bool, tableValued bool) {
+	if lastValueToken == nil {
+		return true, false
+	}
+	switch {
+	case lastValueToken.isTableIndicator:
+		if lastValueToken.Type == COMMAND || strings.EqualFold(lastValueToken.Value, "FROM") {
+			// FROM and JOIN, UPDATE t(a) is not valid
+			return !strings.EqualFold(lastValueToken.Value, "UPDATE"), true
+		}
+		// INTO, TABLE, EXISTS and ONLY are followed by a table
+		return false, false
+	case inTableList && lastValueToken.Type == PUNCTUATION && lastValueToken.Value == ",":
+		return true, true
+	case lastValueToken.Type == KEYWORD && strings.EqualFold(lastValueToken.Value, "LATERAL"):
+		return true, true
+	case lastValueToken.Type == ALIAS_INDICATOR, lastValueToken.Type == PROC_INDICATOR, lastValueToken.Type == CTE_INDICATOR:
+		// column aliases, e.g. AS t(a, b), and routine or CTE definitions
+		return false, false
+	case strings.EqualFold(lastValueToken.Value, "REFERENCES"), strings.EqualFold(lastValueToken.Value, "FUNCTION"),
+		strings.EqualFold(lastValueToken.Value, "TRIGGER"), strings.EqualFold(lastValueToken.Value, "TYPE"):
+		return false, false
+	}
+	return true, false
+}
+
+func isFunctionSuffixWord(token *Token, word string) bool {
+	return (token.Type == IDENT || token.Type == KEYWORD) && strings.EqualFold(token.Value, word)
+}
+
+// addFunctions adds the collected function calls to the statement metadata
+func (m *metadataSet) addFunctions(set map[Function]struct{}, statementMetadata *StatementMetadata) {
+	for _, function := range m.functions.calls {
+		if _, exists := set[function]; exists {
+			continue
+		}
+		function = Function{
+			Name: strings.Clone(function.Name),
+			Kind: function.Kind,
+		}
+		set[function] = struct{}{}
+		statementMetadata.Functions = append(statementMetadata.Functions, function)
+		m.size += len(function.Name) + len(function.Kind)
+	}
+}
@@ -0,0 +1,94 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerCollectFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Function
+	}{
+		{
+			name:  "scalar and aggregate",
+			input: "SELECT lower(name), COUNT(*), SUM(amount) FROM users WHERE created_at > now() GROUP BY lower(name)",
+			expected: []Function{
+				{Name: "lower", Kind: FunctionKindScalar},
+				{Name: "COUNT", Kind: FunctionKindAggregate},
+				{Name: "SUM", Kind: FunctionKindAggregate},
+				{Name: "now", Kind: FunctionKindScalar},
+			},
+		},
+		{
+			name:  "window",
+			input: "SELECT ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at), SUM(amount) OVER w FROM orders",
+			expected: []Function{
+				{Name: "ROW_NUMBER", Kind: FunctionKindWindow},
+				{Name: "SUM", Kind: FunctionKindWindow},
+			},
+		},
+		{
+			name:  "aggregate with filter and within group",
+			input: "SELECT count(*) FILTER (WHERE abs(x) > 1), percentile_cont(0.5) WITHIN GROUP (ORDER BY x), count(id) FILTER (WHERE y) OVER () FROM t",
+			expected: []Function{
+				{Name: "count", Kind: FunctionKindAggregate},
+				{Name: "abs", Kind: FunctionKindScalar},
+				{Name: "percentile_cont", Kind: FunctionKindAggregate},
+				{Name: "count", Kind: FunctionKindWindow},
+			},
+		},
+		{
+			name:  "nested calls",
+			input: "SELECT coalesce(max(a), random()) FROM t",
+			expected: []Function{
+				{Name: "coalesce", Kind: FunctionKindScalar},
+				{Name: "max", Kind: FunctionKindAggregate},
+				{Name: "random", Kind: FunctionKindScalar},
+			},
+		},
+		{
+			name:  "table valued",
+			input: "SELECT * FROM generate_series(1, 10) AS g(n), unnest(ARRAY[1, 2]) JOIN LATERAL jsonb_each(doc) ON true",
+			expected: []Function{
+				{Name: "generate_series", Kind: FunctionKindTable},
+				{Name: "unnest", Kind: FunctionKindTable},
+				{Name: "jsonb_each", Kind: FunctionKindTable},
+			},
+		},
+		{
+			name:     "table and cte column lists",
+			input:    "WITH x(a) AS (SELECT 1) INSERT INTO t(a) SELECT a FROM x",
+			expected: nil,
+		},
+		{
+			name:     "index column list",
+			input:    "CREATE INDEX idx ON users(email)",
+			expected: nil,
+		},
+		{
+			name:  "user defined function",
+			input: "SELECT app.calculate_score(u.id) FROM users u",
+			expected: []Function{
+				{Name: "app.calculate_score", Kind: FunctionKindScalar},
+			},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectFunctions(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, statementMetadata.Functions)
+		})
+	}
+}
+
+func TestNormalizerCollectFunctionsDisabled(t *testing.T) {
+	_, statementMetadata, err := NewNormalizer(WithCollectTables(true)).Normalize("SELECT now() FROM users")
+	assert.NoError(t, err)
+	assert.Nil(t, statementMetadata.Functions)
+}
@@ -0,0 +1,296 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// Hint is an optimizer hint extracted from a SQL statement.
+// Table and Index are set when the hint targets a table or an index.
+type Hint struct {
+	Name  string `json:"name"`
+	Table string `json:"table,omitempty"`
+	Index string `json:"index,omitempty"`
+}
+
+// settingHints are comment hints whose arguments are settings rather than tables
+var settingHints = map[string]bool{
+	"SET":                       true, // pg_hint_plan
+	"SET_VAR":                   true, // MySQL
+	"OPT_PARAM":                 true, // Oracle
+	"QB_NAME":                   true,
+	"MAX_EXECUTION_TIME":        true,
+	"RESOURCE_GROUP":            true,
+	"OPTIMIZER_FEATURES_ENABLE": true,
+	"FIRST_ROWS":                true,
+}
+
+// isIndexHintName reports whether the arguments of a comment hint are a table followed by indexes,
+// e.g. INDEX(t idx), NO_INDEX_FFS(t idx), IndexScan(t idx) or BitmapScan(t idx)
+func isIndexHintName(name string) bool {
+	return strings.Contains(name, "INDEX") || (strings.HasSuffix(name, "SCAN") && name != "SEQSCAN" && name != "NOSEQSCAN" && name != "TIDSCAN" && name != "NOTIDSCAN")
+}
+
+// parseCommentHints parses the hints of an optimizer hint comment,
+// e.g. /*+ INDEX(t idx) FULL(u) */ or --+ ORDERED
+func parseCommentHints(comment string) []Hint {
+	var body string
+	switch {
+	case strings.HasPrefix(comment, "/*+"):
+		body = strings.TrimSuffix(comment[3:], "*/")
+	case strings.HasPrefix(comment, "--+"):
+		body = comment[3:]
+	default:
+		return nil
+	}
+
+	var hints []Hint
+	var name string
+	var args []string
+	depth := 0
+	flush := func() {
+		if name != "" {
+			hints = append(hints, commentHints(name, args)...)
+		}
+		name = ""
+		args = args[:0]
+	}
+
+	for token := range New(body).ValueTokens() {
+		switch {
+		case token.Type == PUNCTUATION && token.Value == "(":
+			depth++
+		case token.Type == PUNCTUATION && token.Value == ")":
+			if depth > 0 {
+				depth--
+			}
+			if depth == 0 {
+				flush()
+			}
+		case depth == 0 && isHintWord(&token):
+			// a hint without arguments, e.g. ORDERED
+			flush()
+			name = strings.ToUpper(token.Value)
+		case depth == 1 && (token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == KEYWORD):
+			arg := token.Value
+			if token.Type == QUOTED_IDENT {
+				arg = trimQuotes(&token)
+			}
+			// strip the query block name, e.g. t@sel$1
+			if i := strings.IndexByte(arg, '@'); i > 0 {
+				arg = arg[:i]
+			}
+			args = append(args, arg)
+		}
+	}
+	flush()
+	return hints
+}
+
+// commentHints expands a comment hint and its arguments into one Hint per target
+func commentHints(name string, args []string) []Hint {
+	if len(args) == 0 || settingHints[name] {
+		return []Hint{{Name: name}}
+	}
+	if isIndexHintName(name) {
+		if len(args) == 1 {
+			return []Hint{{Name: name, Table: args[0]}}
+		}
+		hints := make([]Hint, 0, len(args)-1)
+		for _, index := range args[1:] {
+			hints = append(hints, Hint{Name: name, Table: args[0], Index: index})
+		}
+		return hints
+	}
+	hints := make([]Hint, 0, len(args))
+	for _, table := range args {
+		hints = append(hints, Hint{Name: name, Table: table})
+	}
+	return hints
+}
+
+func isHintWord(token *Token) bool {
+	return token.Type == IDENT || token.Type == KEYWORD || token.Type == COMMAND || token.Type == FUNCTION
+}
+
+type hintClause int
+
+const (
+	hintClauseNone       hintClause = iota
+	hintClauseTable                 // SQL Server table hints, e.g. WITH (NOLOCK, INDEX(idx))
+	hintClauseQuery                 // SQL Server query hints, e.g. OPTION (RECOMPILE, MAXDOP 1)
+	hintClauseIndexIntro            // MySQL index hint before its list, e.g. USE INDEX FOR JOIN
+	hintClauseIndex                 // MySQL index hint list, e.g. USE INDEX (idx1, idx2)
+)
+
+// hintCollector extracts hints from the token stream of a statement
+type hintCollector struct {
+	clause      hintClause
+	depth       int
+	name        string   // name of the hint being read
+	words       []string // words of a query hint, e.g. HASH JOIN
+	args        []string // index arguments of a table hint, e.g. INDEX(idx)
+	afterEquals bool     // table hint in the form INDEX = idx
+	lastTable   string   // last table referenced in the statement
+	prevType    TokenType
+	prevValue   string
+	prev2Type   TokenType
+	prev2Value  string
+	found       []Hint // hints completed by the current token
+}
+
+// collect reads the next token of the statement and returns the hints it completes, if any.
+// The returned slice is only valid until the next call.
+func (h *hintCollector) collect(token *Token) []Hint {
+	h.found = h.found[:0]
+	if token.Type == COMMENT || token.Type == MULTILINE_COMMENT {
+		h.found = append(h.found, parseCommentHints(token.Value)...)
+		return h.found
+	}
+	if !isValueToken(token) {
+		return nil
+	}
+	defer h.update(token)
+
+	switch h.clause {
+	case hintClauseNone:
+		if token.Type == PUNCTUATION && token.Value == "(" && h.prevType == CTE_INDICATOR &&
+			(h.prev2Type == IDENT || h.prev2Type == QUOTED_IDENT) {
+			// WITH ( after a table or alias, a CTE is always WITH name AS (...)
+			h.startClause(hintClauseTable)
+		} else if token.Type == PUNCTUATION && token.Value == "(" && (h.prevType == IDENT || h.prevType == FUNCTION) && strings.EqualFold(h.prevValue, "OPTION") {
+			h.startClause(hintClauseQuery)
+		} else if token.Type == KEYWORD && (strings.EqualFold(token.Value, "INDEX") || strings.EqualFold(token.Value, "KEY")) && isIndexHintVerb(h.prevValue) {
+			h.clause = hintClauseIndexIntro
+			h.name = strings.ToUpper(h.prevValue) + " " + strings.ToUpper(token.Value)
+		}
+	case hintClauseIndexIntro:
+		if token.Type == PUNCTUATION && token.Value == "(" {
+			h.clause = hintClauseIndex
+			h.depth = 1
+			h.args = h.args[:0]
+		} else if !isIndexHintScope(token.Value) {
+			h.reset()
+		}
+	case hintClauseIndex:
+		switch {
+		case token.Type == PUNCTUATION && token.Value == ")":
+			if len(h.args) == 0 {
+				h.add(Hint{Name: h.name, Table: h.lastTable})
+			}
+			for _, index := range h.args {
+				h.add(Hint{Name: h.name, Table: h.lastTable, Index: index})
+			}
+			h.reset()
+		case token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == KEYWORD:
+			h.args = append(h.args, hintArgument(token))
+		}
+	case hintClauseTable, hintClauseQuery:
+		h.collectClause(token)
+	}
+	return h.found
+}
+
+// collectClause reads the content of a WITH (...) table hint or OPTION (...) query hint clause
+func (h *hintCollector) collectClause(token *Token) {
+	switch {
+	case token.Type == PUNCTUATION && token.Value == "(":
+		h.depth++
+	case token.Type == PUNCTUATION && token.Value == ")":
+		h.depth--
+		if h.depth == 0 {
+			h.flushClause()
+			h.reset()
+		}
+	case token.Type == PUNCTUATION && token.Value == "," && h.depth == 1:
+		h.flushClause()
+	case token.Type == OPERATOR && token.Value == "=" && h.depth == 1:
+		h.afterEquals = true
+	case h.depth == 1 && h.afterEquals && (token.Type == IDENT || token.Type == QUOTED_IDENT):
+		h.args = append(h.args, hintArgument(token))
+	case h.depth == 1 && isHintWord(token):
+		h.words = append(h.words, strings.ToUpper(token.Value))
+	case h.depth == 2 && h.clause == hintClauseTable && (token.Type == IDENT || token.Type == QUOTED_IDENT):
+		h.args = append(h.args, hintArgument(token))
+	}
+}
+
+func (h *hintCollector) flushClause() {
+	if len(h.words) > 0 {
+		name := strings.Join(h.words, " ")
+		if h.clause == hintClauseQuery {
+			h.add(Hint{Name: name})
+		} else if len(h.args) == 0 {
+			h.add(Hint{Name: name, Table: h.lastTable})
+		} else {
+			for _, index := range h.args {
+				h.add(Hint{Name: name, Table: h.lastTable, Index: index})
+			}
+		}
+	}
+	h.words = h.words[:0]
+	h.args = h.args[:0]
+	h.afterEquals = false
+}
+
+func (h *hintCollector) add(hint Hint) {
+	h.found = append(h.found, hint)
+}
+
+func (h *hintCollector) startClause(clause hintClause) {
+	h.clause = clause
+	h.depth = 1
+	h.words = h.words[:0]
+	h.args = h.args[:0]
+	h.afterEquals = false
+}
+
+func (h *hintCollector) reset() {
+	h.clause = hintClauseNone
+	h.depth = 0
+	h.name = ""
+	h.words = h.words[:0]
+	h.args = h.args[:0]
+	h.afterEquals = false
+}
+
+func (h *hintCollector) update(token *Token) {
+	h.prev2Type, h.prev2Value = h.prevType, h.prevValue
+	h.prevType, h.prevValue = token.Type, token.Value
+}
+
+// hintArgument returns the table or index name of a hint argument token
+func hintArgument(token *Token) string {
+	if token.Type == QUOTED_IDENT {
+		// trim a copy, trimQuotes resets the state of the token being normalized
+		quoted := *token
+		return trimQuotes(&quoted)
+	}
+	return token.Value
+}
+
+// isIndexHintVerb reports whether value starts a MySQL index hint, e.g. USE INDEX
+func isIndexHintVerb(value string) bool {
+	return strings.EqualFold(value, "USE") || strings.EqualFold(value, "FORCE") || strings.EqualFold(value, "IGNORE")
+}
+
+// isIndexHintScope reports whether value is part of a MySQL index hint scope, e.g. FOR ORDER BY
+func isIndexHintScope(value string) bool {
+	return strings.EqualFold(value, "FOR") || strings.EqualFold(value, "JOIN") ||
+		strings.EqualFold(value, "ORDER") || strings.EqualFold(value, "GROUP") || strings.EqualFold(value, "BY")
+}
+
+// addHint adds a hint to the metadata if it doesn't exist yet.
+// The hint fields are cloned for the same reason as in addMetadata.
+func (m *metadataSet) addHint(hint Hint, set map[Hint]struct{}, statementMetadata *StatementMetadata) {
+	if _, exists := set[hint]; exists {
+		return
+	}
+	hint = Hint{
+		Name:  strings.Clone(hint.Name),
+		Table: strings.Clone(hint.Table),
+		Index: strings.Clone(hint.Index),
+	}
+	set[hint] = struct{}{}
+	statementMetadata.Hints = append(statementMetadata.Hints, hint)
+	m.size += len(hint.Name) + len(hint.Table) + len(hint.Index)
+}
@@ -0,0 +1,141 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommentHints(t *testing.T) {
+	tests := []struct {
+		comment  string
+		expected []Hint
+	}{
+		{
+			comment:  "/*+ INDEX(users idx_users_id) */",
+			expected: []Hint{{Name: "INDEX", Table: "users", Index: "idx_users_id"}},
+		},
+		{
+			comment: "/*+ ORDERED USE_NL(a b) FULL(c) */",
+			expected: []Hint{
+				{Name: "ORDERED"},
+				{Name: "USE_NL", Table: "a"},
+				{Name: "USE_NL", Table: "b"},
+				{Name: "FULL", Table: "c"},
+			},
+		},
+		{
+			comment: "/*+ NO_INDEX(t1 idx1, idx2) MAX_EXECUTION_TIME(1000) */",
+			expected: []Hint{
+				{Name: "NO_INDEX", Table: "t1", Index: "idx1"},
+				{Name: "NO_INDEX", Table: "t1", Index: "idx2"},
+				{Name: "MAX_EXECUTION_TIME"},
+			},
+		},
+		{
+			comment: "/*+ SeqScan(a) IndexScan(b b_pkey) Set(enable_hashjoin off) */",
+			expected: []Hint{
+				{Name: "SEQSCAN", Table: "a"},
+				{Name: "INDEXSCAN", Table: "b", Index: "b_pkey"},
+				{Name: "SET"},
+			},
+		},
+		{
+			comment:  `/*+ INDEX(e@sel$1 "EMP_IDX") */`,
+			expected: []Hint{{Name: "INDEX", Table: "e", Index: "EMP_IDX"}},
+		},
+		{
+			comment:  "--+ FIRST_ROWS(10)",
+			expected: []Hint{{Name: "FIRST_ROWS"}},
+		},
+		{
+			comment:  "/* INDEX(users idx) */",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseCommentHints(tt.comment))
+		})
+	}
+}
+
+func TestNormalizerCollectHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  []Hint
+		lexerOpts []lexerOption
+	}{
+		{
+			name:     "comment hint",
+			input:    "SELECT /*+ INDEX(u idx_users_email) */ * FROM users u WHERE email = ?",
+			expected: []Hint{{Name: "INDEX", Table: "u", Index: "idx_users_email"}},
+		},
+		{
+			name:  "sql server table hints",
+			input: "SELECT * FROM orders o WITH (NOLOCK) JOIN [dbo].[customers] WITH (INDEX(ix_customers_name), FORCESEEK) ON o.customer_id = customers.id",
+			expected: []Hint{
+				{Name: "NOLOCK", Table: "orders"},
+				{Name: "INDEX", Table: "dbo.customers", Index: "ix_customers_name"},
+				{Name: "FORCESEEK", Table: "dbo.customers"},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:      "sql server index hint with equals",
+			input:     "UPDATE orders WITH (ROWLOCK, INDEX = ix_orders_status) SET status = ? WHERE id = ?",
+			expected:  []Hint{{Name: "ROWLOCK", Table: "orders"}, {Name: "INDEX", Table: "orders", Index: "ix_orders_status"}},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:  "sql server query hints",
+			input: "SELECT * FROM orders WHERE id = @id OPTION (RECOMPILE, MAXDOP 1, HASH JOIN, OPTIMIZE FOR (@id UNKNOWN))",
+			expected: []Hint{
+				{Name: "RECOMPILE"},
+				{Name: "MAXDOP"},
+				{Name: "HASH JOIN"},
+				{Name: "OPTIMIZE FOR"},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:  "mysql index hints",
+			input: "SELECT * FROM t1 USE INDEX (i1, i2) JOIN t2 FORCE INDEX FOR JOIN (PRIMARY) ON t1.id = t2.id JOIN t3 IGNORE KEY ()",
+			expected: []Hint{
+				{Name: "USE INDEX", Table: "t1", Index: "i1"},
+				{Name: "USE INDEX", Table: "t1", Index: "i2"},
+				{Name: "FORCE INDEX", Table: "t2", Index: "PRIMARY"},
+				{Name: "IGNORE KEY", Table: "t3"},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:  "cte is not a table hint",
+			input: "WITH (SELECT 1) AS x SELECT * FROM x",
+		},
+		{
+			name:  "postgres storage parameters are not hints",
+			input: "CREATE TABLE t (id int) WITH (fillfactor = 70)",
+		},
+		{
+			name:  "duplicate hints",
+			input: "SELECT /*+ FULL(a) */ * FROM a UNION SELECT /*+ FULL(a) */ * FROM a",
+			expected: []Hint{
+				{Name: "FULL", Table: "a"},
+			},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectHints(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input, tt.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, statementMetadata.Hints)
+			// hints are not collected as tables
+			assert.Equal(t, []string{}, statementMetadata.Tables)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+type indexRole int
+
+const (
+	indexRoleNone  indexRole = iota
+	indexRoleName            // the token is an index name, e.g. idx in CREATE INDEX idx ON t
+	indexRoleTable           // the token is the table of an index, e.g. t in CREATE INDEX idx ON t
+)
+
+type indexState int
+
+const (
+	indexStateNone        indexState = iota
+	indexStateExpectName             // after INDEX, e.g. CREATE INDEX [CONCURRENTLY] [IF NOT EXISTS]
+	indexStateAfterName              // after the index name, e.g. DROP INDEX idx[, idx2] [ON t]
+	indexStateExpectTable            // after ON, e.g. CREATE INDEX idx ON [ONLY] t
+)
+
+// indexCollector tracks index DDL statements (CREATE INDEX, DROP INDEX, ALTER INDEX,
+// ALTER TABLE ... ADD INDEX) to tell index names apart from table names.
+type indexCollector struct {
+	dbms         DBMSType
+	firstCommand string // first command of the current statement
+	state        indexState
+}
+
+// role reads the next value token of the statement and returns its role in an index DDL.
+func (c *indexCollector) role(token *Token) indexRole {
+	if token.Type == PUNCTUATION && token.Value == ";" {
+		c.firstCommand = ""
+		c.state = indexStateNone
+		return indexRoleNone
+	}
+	if c.firstCommand == "" && token.Type == COMMAND {
+		c.firstCommand = token.Value
+	}
+
+	switch c.state {
+	case indexStateNone:
+		if token.Type == KEYWORD && strings.EqualFold(token.Value, "INDEX") && isIndexDDLCommand(c.firstCommand) {
+			c.state = indexStateExpectName
+		}
+	case indexStateExpectName:
+		switch {
+		case token.Type == IDENT && strings.EqualFold(token.Value, "CONCURRENTLY"):
+		case token.Type == KEYWORD && (strings.EqualFold(token.Value, "IF") || strings.EqualFold(token.Value, "NOT") || strings.EqualFold(token.Value, "EXISTS")):
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "ON"):
+			// unnamed index, e.g. CREATE INDEX ON t (col)
+			c.state = indexStateExpectTable
+		case token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION:
+			c.state = indexStateAfterName
+			return indexRoleName
+		default:
+			c.state = indexStateNone
+		}
+	case indexStateAfterName:
+		switch {
+		case token.Type == PUNCTUATION && token.Value == ",":
+			c.state = indexStateExpectName
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "ON"):
+			c.state = indexStateExpectTable
+		default:
+			c.state = indexStateNone
+		}
+	case indexStateExpectTable:
+		switch {
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "ONLY"):
+		case token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION:
+			c.state = indexStateNone
+			return indexRoleTable
+		default:
+			c.state = indexStateNone
+		}
+	}
+	return indexRoleNone
+}
+
+func isIndexDDLCommand(command string) bool {
+	return strings.EqualFold(command, "CREATE") || strings.EqualFold(command, "DROP") || strings.EqualFold(command, "ALTER")
+}
+
+// splitTableIndex splits a CockroachDB index reference table@idx into its table and index.
+// The index is empty if the name does not reference an index.
+// In Oracle, table@dblink references a database link and is kept as is.
+func (c *indexCollector) splitTableIndex(name string) (table string, index string) {
+	if c.dbms == DBMSOracle {
+		return name, ""
+	}
+	i := strings.IndexByte(name, '@')
+	if i <= 0 {
+		return name, ""
+	}
+	return name[:i], name[i+1:]
+}
@@ -0,0 +1,124 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerCollectIndexes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		tables    []string
+		indexes   []string
+		lexerOpts []lexerOption
+	}{
+		{
+			name:    "create index",
+			input:   "CREATE INDEX idx_users_email ON users (email)",
+			tables:  []string{"users"},
+			indexes: []string{"idx_users_email"},
+		},
+		{
+			name:    "create unique index concurrently if not exists",
+			input:   "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email ON ONLY users USING btree (email)",
+			tables:  []string{"users"},
+			indexes: []string{"idx_users_email"},
+		},
+		{
+			name:    "create unnamed index",
+			input:   "CREATE INDEX ON users (email)",
+			tables:  []string{"users"},
+			indexes: nil,
+		},
+		{
+			name:      "sql server clustered index",
+			input:     "CREATE UNIQUE CLUSTERED INDEX [IX_Orders] ON [dbo].[Orders]([OrderID])",
+			tables:    []string{"dbo.Orders"},
+			indexes:   []string{"IX_Orders"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:    "drop index list",
+			input:   "DROP INDEX IF EXISTS idx_a, idx_b",
+			tables:  []string{},
+			indexes: []string{"idx_a", "idx_b"},
+		},
+		{
+			name:      "mysql drop index on table",
+			input:     "DROP INDEX idx_a ON t1",
+			tables:    []string{"t1"},
+			indexes:   []string{"idx_a"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:      "alter index rebuild",
+			input:     "ALTER INDEX emp_idx REBUILD",
+			tables:    []string{},
+			indexes:   []string{"emp_idx"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+		{
+			name:      "alter table add index",
+			input:     "ALTER TABLE t1 ADD INDEX idx_b (b)",
+			tables:    []string{"t1"},
+			indexes:   []string{"idx_b"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:      "mysql index hint",
+			input:     "SELECT * FROM t1 USE INDEX (i1) JOIN t2 FORCE INDEX (i2) ON t1.id = t2.id",
+			tables:    []string{"t1", "t2"},
+			indexes:   []string{"i1", "i2"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:    "comment hint",
+			input:   "SELECT /*+ INDEX(users idx_users_email) */ * FROM users",
+			tables:  []string{"users"},
+			indexes: []string{"idx_users_email"},
+		},
+		{
+			name:    "cockroachdb index reference",
+			input:   "SELECT * FROM users@users_email_idx WHERE email = ?",
+			tables:  []string{"users"},
+			indexes: []string{"users_email_idx"},
+		},
+		{
+			name:      "oracle database link",
+			input:     "SELECT * FROM emp@remote_db",
+			tables:    []string{"emp@remote_db"},
+			indexes:   nil,
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+		{
+			name:    "index statement followed by another statement",
+			input:   "CREATE INDEX idx ON t1 (a); SELECT * FROM t2 JOIN t3 ON t2.id = t3.id",
+			tables:  []string{"t1", "t2", "t3"},
+			indexes: []string{"idx"},
+		},
+	}
+
+	normalizer := NewNormalizer(
+		WithCollectTables(true),
+		WithCollectIndexes(true),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input, tt.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tables, statementMetadata.Tables)
+			assert.Equal(t, tt.indexes, statementMetadata.Indexes)
+		})
+	}
+}
+
+func TestNormalizerIndexDDLTablesWithCollectIndexes(t *testing.T) {
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectIndexes(true))
+	_, statementMetadata, err := normalizer.Normalize("CREATE INDEX IF NOT EXISTS idx_users_email ON users (email)")
+	assert.NoError(t, err)
+	// the index name after EXISTS is not mistaken for a table when index DDL is tracked
+	assert.Equal(t, []string{"users"}, statementMetadata.Tables)
+	assert.Equal(t, []string{"idx_users_email"}, statementMetadata.Indexes)
+}
@@ -0,0 +1,247 @@
+package sqllexer
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// InjectionKind is the kind of a SQL injection heuristic
+type InjectionKind string
+
+const (
+	InjectionTautology          InjectionKind = "tautology"           // e.g. OR 1=1, OR 'a'='a'
+	InjectionStackedQuery       InjectionKind = "stacked_query"       // e.g. '; DROP TABLE users
+	InjectionUnionNullPadding   InjectionKind = "union_null_padding"  // e.g. UNION SELECT NULL, NULL
+	InjectionCommentTruncation  InjectionKind = "comment_truncation"  // e.g. 'admin'--
+	InjectionTimeDelay          InjectionKind = "time_delay"          // e.g. SLEEP(5), WAITFOR DELAY '0:0:5'
+	InjectionHexPayload         InjectionKind = "hex_payload"         // e.g. 0x61646d696e
+	InjectionUnterminatedString InjectionKind = "unterminated_string" // e.g. WHERE name = 'abc
+)
+
+// injectionScores are the scores of the heuristics, out of 100
+var injectionScores = map[InjectionKind]int{
+	InjectionTautology:          40,
+	InjectionStackedQuery:       35,
+	InjectionUnionNullPadding:   40,
+	InjectionCommentTruncation:  30,
+	InjectionTimeDelay:          50,
+	InjectionHexPayload:         20,
+	InjectionUnterminatedString: 20,
+}
+
+// InjectionFinding is a SQL injection heuristic that fired on a token
+type InjectionFinding struct {
+	Kind  InjectionKind `json:"kind"`
+	Score int           `json:"score"`
+	// Offset is the byte offset of the token in the input
+	Offset int    `json:"offset"`
+	Value  string `json:"value"`
+}
+
+// InjectionReport holds the findings of DetectInjection.
+// Score is the sum of the scores of the findings, capped at 100.
+type InjectionReport struct {
+	Score    int                `json:"score"`
+	Findings []InjectionFinding `json:"findings"`
+}
+
+// injectionToken is a value token with its byte offset
+type injectionToken struct {
+	Token
+	offset int
+	// adjacent is true when the token directly follows the previous value token, without space or comment
+	adjacent bool
+}
+
+// DetectInjection looks for SQL injection patterns in the raw tokens of a query.
+// It must run on the query before obfuscation, as obfuscation removes the literals the heuristics rely on.
+func DetectInjection(input string, lexerOpts ...lexerOption) *InjectionReport {
+	report := &InjectionReport{Findings: []InjectionFinding{}}
+
+	var tokens []injectionToken
+	offset := 0
+	adjacent := false
+	lexer := New(input, lexerOpts...)
+	for token := range lexer.All() {
+		tokenOffset := offset
+		offset += len(token.Value)
+
+		switch token.Type {
+		case SPACE:
+			adjacent = false
+			continue
+		case COMMENT, MULTILINE_COMMENT:
+			// a comment right after a string truncates the rest of the query, e.g. 'admin'--
+			if adjacent && len(tokens) > 0 && isStringToken(tokens[len(tokens)-1].Type) {
+				report.add(InjectionCommentTruncation, tokenOffset, token.Value)
+			}
+			adjacent = false
+			continue
+		case INCOMPLETE_STRING:
+			report.add(InjectionUnterminatedString, tokenOffset, token.Value)
+		}
+		tokens = append(tokens, injectionToken{Token: token, offset: tokenOffset, adjacent: adjacent})
+		adjacent = true
+	}
+
+	for i := range tokens {
+		token := &tokens[i]
+		switch {
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "OR"):
+			if isTautology(tokens[i+1:]) {
+				report.add(InjectionTautology, token.offset, token.Value)
+			}
+		case token.Type == PUNCTUATION && token.Value == ";":
+			if i > 0 && isStringToken(tokens[i-1].Type) && i+1 < len(tokens) && tokens[i+1].Type == COMMAND {
+				report.add(InjectionStackedQuery, tokens[i+1].offset, tokens[i+1].Value)
+			}
+		case token.Type == KEYWORD && strings.EqualFold(token.Value, "UNION"):
+			if isNullPadding(tokens[i+1:]) {
+				report.add(InjectionUnionNullPadding, token.offset, token.Value)
+			}
+		case token.Type == FUNCTION && isDelayFunction(token.Value):
+			report.add(InjectionTimeDelay, token.offset, token.Value)
+		case token.Type == IDENT && strings.EqualFold(token.Value, "WAITFOR"):
+			if i+1 < len(tokens) && (strings.EqualFold(tokens[i+1].Value, "DELAY") || strings.EqualFold(tokens[i+1].Value, "TIME")) {
+				report.add(InjectionTimeDelay, token.offset, token.Value)
+			}
+		case token.Type == NUMBER && isHexPayload(token.Value):
+			report.add(InjectionHexPayload, token.offset, token.Value)
+		case token.Type == IDENT && strings.EqualFold(token.Value, "X"):
+			// outside of MySQL, x'61646d696e' lexes as an identifier prefix followed by a string
+			if i+1 < len(tokens) && tokens[i+1].adjacent && tokens[i+1].Type == STRING && isHexPayload("0x"+strings.Trim(tokens[i+1].Value, "'")) {
+				report.add(InjectionHexPayload, token.offset, token.Value+tokens[i+1].Value)
+			}
+		}
+	}
+	return report
+}
+
+func (r *InjectionReport) add(kind InjectionKind, offset int, value string) {
+	score := injectionScores[kind]
+	r.Findings = append(r.Findings, InjectionFinding{
+		Kind:   kind,
+		Score:  score,
+		Offset: offset,
+		Value:  strings.Clone(value),
+	})
+	r.Score = min(r.Score+score, 100)
+}
+
+func isStringToken(tokenType TokenType) bool {
+	return tokenType == STRING || tokenType == INCOMPLETE_STRING
+}
+
+// isTautology reports whether the tokens following an OR form an always true condition,
+// e.g. 1=1, 'a'='a', 2>1, TRUE or a lone literal at the end of the query
+func isTautology(tokens []injectionToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	left := &tokens[0]
+	if !isLiteral(left.Type) {
+		return false
+	}
+	if len(tokens) < 3 || tokens[1].Type != OPERATOR {
+		// OR TRUE, OR 1
+		return left.Type == BOOLEAN && strings.EqualFold(left.Value, "TRUE") ||
+			left.Type == NUMBER && left.Value != "0" && (len(tokens) == 1 || tokens[1].Value == ";" || tokens[1].Value == ")")
+	}
+	right := &tokens[2]
+	if !isLiteral(right.Type) {
+		return false
+	}
+	leftNumber, leftErr := strconv.ParseFloat(left.Value, 64)
+	rightNumber, rightErr := strconv.ParseFloat(right.Value, 64)
+	if leftErr == nil && rightErr == nil {
+		switch tokens[1].Value {
+		case "=", "==", "<=", ">=", "<=>":
+			return leftNumber == rightNumber || (tokens[1].Value == "<=" && leftNumber < rightNumber) || (tokens[1].Value == ">=" && leftNumber > rightNumber)
+		case "<":
+			return leftNumber < rightNumber
+		case ">":
+			return leftNumber > rightNumber
+		case "<>", "!=":
+			return leftNumber != rightNumber
+		}
+		return false
+	}
+	switch tokens[1].Value {
+	case "=", "==", "<=>":
+		return left.Value == right.Value
+	case "<>", "!=":
+		return left.Value != right.Value
+	}
+	return false
+}
+
+func isLiteral(tokenType TokenType) bool {
+	switch tokenType {
+	case NUMBER, STRING, BOOLEAN:
+		return true
+	}
+	return false
+}
+
+// isNullPadding reports whether the tokens following a UNION select a list of NULLs
+// and numbers padding the columns of the original query, e.g. UNION ALL SELECT NULL, NULL, 3
+func isNullPadding(tokens []injectionToken) bool {
+	i := 0
+	if i < len(tokens) && (strings.EqualFold(tokens[i].Value, "ALL") || strings.EqualFold(tokens[i].Value, "DISTINCT")) {
+		i++
+	}
+	if i >= len(tokens) || tokens[i].Type != COMMAND || !strings.EqualFold(tokens[i].Value, "SELECT") {
+		return false
+	}
+	nulls, columns := 0, 0
+	for i++; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case NULL:
+			nulls++
+			columns++
+		case IDENT:
+			// NULL directly followed by a comment lexes as an identifier
+			if !strings.EqualFold(tokens[i].Value, "NULL") {
+				return nulls > 0 && columns > 1
+			}
+			nulls++
+			columns++
+		case NUMBER, STRING:
+			columns++
+		case PUNCTUATION:
+			if tokens[i].Value != "," {
+				return nulls > 0 && columns > 1
+			}
+		default:
+			return nulls > 0 && columns > 1
+		}
+	}
+	return nulls > 0 && columns > 1
+}
+
+func isDelayFunction(name string) bool {
+	switch strings.ToUpper(name) {
+	case "SLEEP", "PG_SLEEP", "PG_SLEEP_FOR", "BENCHMARK", "DBMS_LOCK.SLEEP", "DBMS_SESSION.SLEEP", "DBMS_PIPE.RECEIVE_MESSAGE":
+		return true
+	}
+	return false
+}
+
+// isHexPayload reports whether a hex number decodes to printable text, e.g. 0x61646d696e or x'61646d696e' is "admin"
+func isHexPayload(value string) bool {
+	radix, start := numberRadix(value)
+	if radix != 'x' || start != 2 {
+		return false
+	}
+	decoded, err := hex.DecodeString(strings.TrimSuffix(value[start:], "'"))
+	if err != nil || len(decoded) < 4 {
+		return false
+	}
+	for _, b := range decoded {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectInjection(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		dbms     DBMSType
+		expected []InjectionFinding
+	}{
+		{
+			name:  "numeric tautology",
+			input: "SELECT * FROM users WHERE id = 1 OR 1=1",
+			expected: []InjectionFinding{
+				{Kind: InjectionTautology, Score: 40, Offset: 33, Value: "OR"},
+			},
+		},
+		{
+			name:  "string tautology",
+			input: "SELECT * FROM users WHERE name = 'a' OR 'a'='a'",
+			expected: []InjectionFinding{
+				{Kind: InjectionTautology, Score: 40, Offset: 37, Value: "OR"},
+			},
+		},
+		{
+			name:  "boolean tautology",
+			input: "SELECT * FROM users WHERE name = 'a' OR TRUE",
+			expected: []InjectionFinding{
+				{Kind: InjectionTautology, Score: 40, Offset: 37, Value: "OR"},
+			},
+		},
+		{
+			name:     "column comparison is not a tautology",
+			input:    "SELECT * FROM users WHERE a = 1 OR b = 1 OR 1 = 2",
+			expected: []InjectionFinding{},
+		},
+		{
+			name:  "stacked query after a string",
+			input: "SELECT * FROM users WHERE name = 'a'; DROP TABLE users",
+			expected: []InjectionFinding{
+				{Kind: InjectionStackedQuery, Score: 35, Offset: 38, Value: "DROP"},
+			},
+		},
+		{
+			name:     "statements after a number are not stacked",
+			input:    "SELECT * FROM users WHERE id = 1; SELECT 2",
+			expected: []InjectionFinding{},
+		},
+		{
+			name:  "union null padding",
+			input: "SELECT name FROM users WHERE id = 1 UNION ALL SELECT NULL, NULL, password FROM admins",
+			expected: []InjectionFinding{
+				{Kind: InjectionUnionNullPadding, Score: 40, Offset: 36, Value: "UNION"},
+			},
+		},
+		{
+			name:     "union of tables",
+			input:    "SELECT name FROM users UNION SELECT name FROM admins",
+			expected: []InjectionFinding{},
+		},
+		{
+			name:  "comment truncation",
+			input: "SELECT * FROM users WHERE name = 'admin'-- ' AND password = 'x'",
+			expected: []InjectionFinding{
+				{Kind: InjectionCommentTruncation, Score: 30, Offset: 40, Value: "-- ' AND password = 'x'"},
+			},
+		},
+		{
+			name:     "comment after a space is not a truncation",
+			input:    "SELECT * FROM users WHERE name = 'admin' -- by name",
+			expected: []InjectionFinding{},
+		},
+		{
+			name:  "sleep",
+			input: "SELECT * FROM users WHERE id = 1 AND SLEEP(5)",
+			dbms:  DBMSMySQL,
+			expected: []InjectionFinding{
+				{Kind: InjectionTimeDelay, Score: 50, Offset: 37, Value: "SLEEP"},
+			},
+		},
+		{
+			name:  "benchmark and pg_sleep",
+			input: "SELECT BENCHMARK(1000000, MD5('a')), pg_sleep(5)",
+			expected: []InjectionFinding{
+				{Kind: InjectionTimeDelay, Score: 50, Offset: 7, Value: "BENCHMARK"},
+				{Kind: InjectionTimeDelay, Score: 50, Offset: 37, Value: "pg_sleep"},
+			},
+		},
+		{
+			name:  "waitfor delay",
+			input: "SELECT * FROM users WHERE name = 'a'; WAITFOR DELAY '0:0:5'",
+			dbms:  DBMSSQLServer,
+			expected: []InjectionFinding{
+				{Kind: InjectionTimeDelay, Score: 50, Offset: 38, Value: "WAITFOR"},
+			},
+		},
+		{
+			name:  "hex payloads",
+			input: "SELECT * FROM users WHERE name = 0x61646d696e OR name = x'61646d696e' OR id = 0x0102",
+			expected: []InjectionFinding{
+				{Kind: InjectionHexPayload, Score: 20, Offset: 33, Value: "0x61646d696e"},
+				{Kind: InjectionHexPayload, Score: 20, Offset: 56, Value: "x'61646d696e'"},
+			},
+		},
+		{
+			name:  "unterminated string",
+			input: "SELECT * FROM users WHERE name = 'abc",
+			expected: []InjectionFinding{
+				{Kind: InjectionUnterminatedString, Score: 20, Offset: 33, Value: "'abc"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := DetectInjection(tt.input, WithDBMS(tt.dbms))
+			assert.Equal(t, tt.expected, report.Findings)
+		})
+	}
+}
+
+func TestDetectInjectionScore(t *testing.T) {
+	report := DetectInjection("SELECT * FROM users WHERE name = '' OR 1=1; DROP TABLE users; SELECT SLEEP(5)")
+	assert.Len(t, report.Findings, 2)
+	assert.Equal(t, 90, report.Score)
+
+	report = DetectInjection("SELECT * FROM users WHERE name = 'a' OR 'a'='a' UNION SELECT NULL, NULL-- '")
+	assert.Len(t, report.Findings, 2)
+	assert.Equal(t, 80, report.Score)
+
+	report = DetectInjection("SELECT * FROM users WHERE name = 'a' OR 'a'='a'; SELECT SLEEP(5)-- '")
+	assert.Len(t, report.Findings, 3)
+	assert.Equal(t, 100, report.Score)
+
+	report = DetectInjection("SELECT * FROM users WHERE id = 1")
+	assert.Empty(t, report.Findings)
+	assert.Equal(t, 0, report.Score)
+}
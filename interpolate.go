@@ -0,0 +1,281 @@
+package sqllexer
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate replaces the placeholders of the input with the values of args, quoted and escaped
+// for the DBMS, e.g. to run EXPLAIN on a prepared statement with its captured bind values.
+// ? placeholders take the next value of args, except in PostgreSQL where ? is a JSON operator.
+// Numbered placeholders ($1, :1, @p1) take the value at their position. Named placeholders (:name, @name)
+// take the sql.NamedArg of the same name, and @p1 also takes a sql.NamedArg named p1 if there is one.
+// Values may be nil, booleans, numbers, strings, []byte, time.Time, driver.Valuer or the Parameter
+// returned by Parameterize.
+func Interpolate(input string, args []any, lexerOpts ...lexerOption) (string, error) {
+	lexer := New(input, lexerOpts...)
+	dbms := lexer.config.DBMS
+
+	named := make(map[string]any)
+	for _, arg := range args {
+		if namedArg, ok := arg.(sql.NamedArg); ok {
+			named[namedArg.Name] = namedArg.Value
+		}
+	}
+
+	var tokens []Token
+	for token := range lexer.All() {
+		tokens = append(tokens, token)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(input))
+	var lastValueToken *Token
+	next := 0
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		placeholder := token.Value
+		var arg any
+		found := false
+		switch {
+		case token.Type == OPERATOR && strings.HasSuffix(token.Value, "?") && dbms != DBMSPostgres:
+			// a placeholder following an operator is lexed with it, e.g. -?
+			builder.WriteString(token.Value[:len(token.Value)-1])
+			placeholder = "?"
+			next++
+			arg, found = positionalArg(args, next)
+		case token.Type == POSITIONAL_PARAMETER:
+			arg, found = numberedArg(args, token.Value[1:])
+		case token.Type == BIND_PARAMETER:
+			name := token.Value[1:]
+			if arg, found = named[name]; !found && (strings.HasPrefix(token.Value, "@p") || strings.HasPrefix(token.Value, "@P")) {
+				arg, found = numberedArg(args, token.Value[2:])
+			} else if !found && token.Value[0] == ':' {
+				arg, found = numberedArg(args, name)
+			}
+		case token.Type == OPERATOR && token.Value == ":" && i+1 < len(tokens) && (tokens[i+1].Type == NUMBER || tokens[i+1].Type == IDENT) &&
+			(lastValueToken == nil || !isInterpolationOperand(lastValueToken)):
+			// outside of Oracle, :1 and :name are lexed as a colon followed by a number or an identifier,
+			// while col:field is a semi-structured data access and arr[:2] a slice
+			i++
+			placeholder += tokens[i].Value
+			if arg, found = named[tokens[i].Value]; !found {
+				arg, found = numberedArg(args, tokens[i].Value)
+			}
+		default:
+			builder.WriteString(token.Value)
+			if isValueToken(token) {
+				lastValueToken = token
+			}
+			continue
+		}
+
+		if !found {
+			return "", fmt.Errorf("missing value for placeholder %s", placeholder)
+		}
+		literal, err := quoteValue(dbms, arg)
+		if err != nil {
+			return "", fmt.Errorf("placeholder %s: %w", placeholder, err)
+		}
+		if strings.HasPrefix(literal, "-") && strings.HasSuffix(builder.String(), "-") {
+			// a-? with -1 would become the comment a--1
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(literal)
+		lastValueToken = token
+	}
+	return builder.String(), nil
+}
+
+// numberedArg returns the value of args at the position of a numbered placeholder, e.g. 2 for $2
+func numberedArg(args []any, digits string) (any, bool) {
+	position, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil, false
+	}
+	return positionalArg(args, position)
+}
+
+// positionalArg returns the value of args at the 1-based position, skipping named arguments
+func positionalArg(args []any, position int) (any, bool) {
+	if position < 1 {
+		return nil, false
+	}
+	n := position
+	for _, arg := range args {
+		if _, ok := arg.(sql.NamedArg); ok {
+			continue
+		}
+		if n--; n == 0 {
+			return arg, true
+		}
+	}
+	return nil, false
+}
+
+// isInterpolationOperand reports whether a colon following the token is part of an expression, e.g. col:field or arr[:2]
+func isInterpolationOperand(token *Token) bool {
+	switch token.Type {
+	case IDENT, QUOTED_IDENT, NUMBER, STRING, FUNCTION:
+		return true
+	case PUNCTUATION:
+		return token.Value == ")" || token.Value == "]" || token.Value == "["
+	}
+	return false
+}
+
+// quoteValue returns the SQL literal of value for the DBMS
+func quoteValue(dbms DBMSType, value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case Parameter:
+		switch v.Type {
+		case ParameterTypeNull:
+			return "NULL", nil
+		case ParameterTypeNumber:
+			if !isNumberLiteral(dbms, v.Value) {
+				return "", fmt.Errorf("invalid number %q", v.Value)
+			}
+			return v.Value, nil
+		case ParameterTypeBoolean:
+			return quoteValue(dbms, strings.EqualFold(v.Value, "TRUE"))
+		}
+		return quoteString(dbms, v.Value), nil
+	case driver.Valuer:
+		driverValue, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		return quoteValue(dbms, driverValue)
+	case bool:
+		switch dbms {
+		case DBMSSQLServer, DBMSOracle:
+			if v {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return quoteFloat(float64(v), 32)
+	case float64:
+		return quoteFloat(v, 64)
+	case string:
+		return quoteString(dbms, v), nil
+	case []byte:
+		return quoteBytes(dbms, v), nil
+	case time.Time:
+		return quoteTime(dbms, v), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", value)
+}
+
+func quoteFloat(v float64, bitSize int) (string, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+	return strconv.FormatFloat(v, 'g', -1, bitSize), nil
+}
+
+// isNumberLiteral reports whether the value lexes as a single well-formed number
+func isNumberLiteral(dbms DBMSType, value string) bool {
+	lexer := New(value, WithDBMS(dbms))
+	token := lexer.Scan()
+	if token.Type != NUMBER || token.IsMalformed() {
+		return false
+	}
+	return lexer.Scan().Type == EOF
+}
+
+// quoteString returns the quoted string, doubling single quotes.
+// Backslashes are escaped in the DBMS where they are an escape character, PostgreSQL strings holding
+// a backslash are written as E'...' strings, and SQL Server prefixes non-ASCII strings with N.
+func quoteString(dbms DBMSType, v string) string {
+	backslash := strings.IndexByte(v, '\\') >= 0 && dbms != DBMSSQLServer && dbms != DBMSOracle
+	var builder strings.Builder
+	builder.Grow(len(v) + 4)
+	if dbms == DBMSSQLServer && !isASCII(v) {
+		builder.WriteByte('N')
+	}
+	if backslash && dbms == DBMSPostgres {
+		// '\' is a backslash with standard_conforming_strings, and an escape without it
+		builder.WriteByte('E')
+	}
+	builder.WriteByte('\'')
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '\'':
+			builder.WriteString("''")
+		case v[i] == '\\' && backslash:
+			builder.WriteString("\\\\")
+		case v[i] == 0 && dbms == DBMSMySQL:
+			builder.WriteString("\\0")
+		default:
+			builder.WriteByte(v[i])
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+func quoteBytes(dbms DBMSType, v []byte) string {
+	encoded := hex.EncodeToString(v)
+	switch dbms {
+	case DBMSPostgres:
+		return "'\\x" + encoded + "'::bytea"
+	case DBMSSQLServer:
+		return "0x" + encoded
+	case DBMSOracle:
+		return "HEXTORAW('" + encoded + "')"
+	}
+	return "X'" + encoded + "'"
+}
+
+func quoteTime(dbms DBMSType, v time.Time) string {
+	switch dbms {
+	case DBMSPostgres, DBMSSnowflake:
+		return "'" + v.Format("2006-01-02 15:04:05.999999999Z07:00") + "'"
+	case DBMSOracle:
+		return "TIMESTAMP '" + v.Format("2006-01-02 15:04:05.999999999") + "'"
+	}
+	return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+}
+
+func isASCII(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if v[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
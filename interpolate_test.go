@@ -0,0 +1,175 @@
+package sqllexer
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	tests := []struct {
+		name     string
+		input    string
+		dbms     DBMSType
+		args     []any
+		expected string
+	}{
+		{
+			name:     "mysql",
+			input:    "SELECT * FROM t WHERE a = ? AND b = ? AND c IN (?, ?) AND d = ?",
+			dbms:     DBMSMySQL,
+			args:     []any{"it's a \\ test", 42, true, nil, 1.5},
+			expected: "SELECT * FROM t WHERE a = 'it''s a \\\\ test' AND b = 42 AND c IN (TRUE, NULL) AND d = 1.5",
+		},
+		{
+			name:     "postgres",
+			input:    "SELECT * FROM t WHERE a = $2 AND b = $1 AND c ? 'key' AND d = $3 AND e = $4",
+			dbms:     DBMSPostgres,
+			args:     []any{"x\\y", int64(-7), []byte("ab"), timestamp},
+			expected: "SELECT * FROM t WHERE a = -7 AND b = E'x\\\\y' AND c ? 'key' AND d = '\\x6162'::bytea AND e = '2024-01-02 03:04:05.6Z'",
+		},
+		{
+			name:     "oracle",
+			input:    "SELECT * FROM t WHERE a = :1 AND b = :name AND c = :2",
+			dbms:     DBMSOracle,
+			args:     []any{"x", sql.Named("name", false), timestamp},
+			expected: "SELECT * FROM t WHERE a = 'x' AND b = 0 AND c = TIMESTAMP '2024-01-02 03:04:05.6'",
+		},
+		{
+			name:     "sql server",
+			input:    "SELECT * FROM t WHERE a = @p1 AND b = @name AND c = @p2",
+			dbms:     DBMSSQLServer,
+			args:     []any{"été", sql.Named("name", []byte{0xff}), true},
+			expected: "SELECT * FROM t WHERE a = N'été' AND b = 0xff AND c = 1",
+		},
+		{
+			name:     "colon placeholders without dbms",
+			input:    "SELECT col:field, arr[:2] FROM t WHERE a = :1 AND b = :name AND c::text = ?",
+			args:     []any{"x", sql.Named("name", uint8(3))},
+			expected: "SELECT col:field, arr[:2] FROM t WHERE a = 'x' AND b = 3 AND c::text = 'x'",
+		},
+		{
+			name:     "negative value after a minus",
+			input:    "SELECT a -?, b - ?",
+			args:     []any{-1, -2},
+			expected: "SELECT a - -1, b - -2",
+		},
+		{
+			name:     "placeholders in strings and comments are kept",
+			input:    "SELECT '?' /* ? */ , ? -- ?",
+			args:     []any{"x"},
+			expected: "SELECT '?' /* ? */ , 'x' -- ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interpolated, err := Interpolate(tt.input, tt.args, WithDBMS(tt.dbms))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, interpolated)
+		})
+	}
+}
+
+func TestInterpolateParameterize(t *testing.T) {
+	input := "SELECT * FROM t WHERE a = 'it''s' AND b IN (1, -2.5) AND c = TRUE AND d = NULL"
+	for _, dbms := range []DBMSType{DBMSPostgres, DBMSMySQL, DBMSOracle, DBMSSQLServer} {
+		t.Run(string(dbms), func(t *testing.T) {
+			parameterized, parameters := Parameterize(input, WithDBMS(dbms))
+			args := make([]any, len(parameters))
+			for i, parameter := range parameters {
+				args[i] = parameter
+			}
+			interpolated, err := Interpolate(parameterized, args, WithDBMS(dbms))
+			assert.NoError(t, err)
+			expected := input
+			if dbms == DBMSOracle || dbms == DBMSSQLServer {
+				expected = "SELECT * FROM t WHERE a = 'it''s' AND b IN (1, -2.5) AND c = 1 AND d = NULL"
+			}
+			assert.Equal(t, expected, interpolated)
+		})
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	_, err := Interpolate("SELECT * FROM t WHERE a = ? AND b = ?", []any{1})
+	assert.EqualError(t, err, "missing value for placeholder ?")
+
+	_, err = Interpolate("SELECT * FROM t WHERE a = :name", []any{sql.Named("other", 1)}, WithDBMS(DBMSOracle))
+	assert.EqualError(t, err, "missing value for placeholder :name")
+
+	_, err = Interpolate("SELECT * FROM t WHERE a = $1", []any{struct{}{}}, WithDBMS(DBMSPostgres))
+	assert.EqualError(t, err, "placeholder $1: unsupported value type struct {}")
+}
+
+func TestInterpolateInjection(t *testing.T) {
+	values := []string{
+		`\' OR 1=1 --`,
+		`\\' OR 1=1 --`,
+		`' OR 1=1 --`,
+		`\`,
+	}
+	escaped := []string{`'\\'' OR 1=1 --'`, `'\\\\'' OR 1=1 --'`, `''' OR 1=1 --'`, `'\\'`}
+	tests := []struct {
+		dbms     DBMSType
+		expected []string
+	}{
+		{dbms: DBMSMySQL, expected: escaped},
+		{dbms: DBMSSnowflake, expected: escaped},
+		{dbms: "", expected: escaped},
+		{dbms: DBMSPostgres, expected: []string{`E'\\'' OR 1=1 --'`, `E'\\\\'' OR 1=1 --'`, `''' OR 1=1 --'`, `E'\\'`}},
+		// backslash is not an escape character in SQL Server and Oracle
+		{dbms: DBMSSQLServer, expected: []string{`'\'' OR 1=1 --'`, `'\\'' OR 1=1 --'`, `''' OR 1=1 --'`, `'\'`}},
+		{dbms: DBMSOracle, expected: []string{`'\'' OR 1=1 --'`, `'\\'' OR 1=1 --'`, `''' OR 1=1 --'`, `'\'`}},
+	}
+
+	for _, tt := range tests {
+		for i, value := range values {
+			t.Run(string(tt.dbms)+" "+value, func(t *testing.T) {
+				input := "SELECT * FROM t WHERE a = ?"
+				if tt.dbms == DBMSPostgres {
+					input = "SELECT * FROM t WHERE a = $1"
+				}
+				interpolated, err := Interpolate(input, []any{value}, WithDBMS(tt.dbms))
+				assert.NoError(t, err)
+				assert.Equal(t, "SELECT * FROM t WHERE a = "+tt.expected[i], interpolated)
+
+				// the value only lexes as strings ending the query, 'it''s' being the adjacent strings 'it' and 's'
+				var literal []Token
+				for token := range New(interpolated, WithDBMS(tt.dbms)).All() {
+					if token.Type == OPERATOR && token.Value == "=" {
+						literal = literal[:0]
+					} else if token.Type != SPACE || len(literal) > 0 {
+						literal = append(literal, token)
+					}
+				}
+				if len(literal) > 1 && literal[0].Value == "E" {
+					literal = literal[1:]
+				}
+				for _, token := range literal {
+					assert.Equal(t, STRING, token.Type, token.Value)
+				}
+			})
+		}
+	}
+}
+
+func TestInterpolateInvalidNumber(t *testing.T) {
+	for _, value := range []string{"1; DROP TABLE t", "1 OR 1=1", "1.2.3", "0x", "", "abc", "1 -- x"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := Interpolate("SELECT * FROM t WHERE a = ?", []any{Parameter{Type: ParameterTypeNumber, Value: value}})
+			assert.EqualError(t, err, "placeholder ?: invalid number "+strconv.Quote(value))
+		})
+	}
+
+	interpolated, err := Interpolate("SELECT * FROM t WHERE a = ? AND b = ?", []any{
+		Parameter{Type: ParameterTypeNumber, Value: "-1.5e3"},
+		Parameter{Type: ParameterTypeNumber, Value: "0x1F"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = -1.5e3 AND b = 0x1F", interpolated)
+}
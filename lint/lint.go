@@ -0,0 +1,250 @@
+// Package lint reports SQL anti-patterns found in the token stream of the sqllexer Lexer.
+package lint
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DataDog/go-sqllexer"
+)
+
+// Severity is the severity of a finding
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Position is the position of a token in the linted input.
+// Line and Column are 1-based, Column counts runes.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Finding is an anti-pattern reported by a rule
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Position Position `json:"position"`
+	// Value is the value of the token the finding is reported on
+	Value string `json:"value"`
+}
+
+// Token is a value token of a statement
+type Token struct {
+	sqllexer.Token
+	Position Position
+	// Depth is the parentheses depth of the token in the statement
+	Depth int
+	// Clause is the clause the token belongs to, e.g. SELECT, FROM, WHERE, GROUP BY or ORDER BY.
+	// Parenthesized expressions belong to the clause of their parentheses, subqueries to their own clauses.
+	Clause string
+}
+
+// Statement holds the value tokens of a statement, spaces and comments excluded
+type Statement struct {
+	Tokens []Token
+}
+
+// Rule checks a statement for an anti-pattern
+type Rule interface {
+	// Name is the name of the rule reported in findings, e.g. select-star
+	Name() string
+	Check(statement *Statement) []Finding
+}
+
+// NewFinding returns a finding of rule reported on token
+func NewFinding(rule Rule, severity Severity, token *Token, message string) Finding {
+	return Finding{
+		Rule:     rule.Name(),
+		Severity: severity,
+		Message:  message,
+		Position: token.Position,
+		Value:    token.Value,
+	}
+}
+
+type linterConfig struct {
+	DBMS  sqllexer.DBMSType
+	Rules []Rule
+}
+
+type linterOption func(*linterConfig)
+
+// WithDBMS sets the DBMS used to tokenize the input
+func WithDBMS(dbms sqllexer.DBMSType) linterOption {
+	return func(c *linterConfig) {
+		c.DBMS = dbms
+	}
+}
+
+// WithRules sets the rules run by the linter, DefaultRules by default.
+// Use WithRules(append(DefaultRules(), rule)...) to add a rule to the default ones.
+func WithRules(rules ...Rule) linterOption {
+	return func(c *linterConfig) {
+		c.Rules = rules
+	}
+}
+
+type Linter struct {
+	config *linterConfig
+}
+
+func New(opts ...linterOption) *Linter {
+	linter := Linter{
+		config: &linterConfig{
+			Rules: DefaultRules(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(linter.config)
+	}
+
+	return &linter
+}
+
+// Lint runs the rules over each statement of the input and returns the findings in order of the rules
+func (l *Linter) Lint(input string) []Finding {
+	var findings []Finding
+	for _, statement := range Split(input, l.config.DBMS) {
+		for _, rule := range l.config.Rules {
+			findings = append(findings, rule.Check(statement)...)
+		}
+	}
+	return findings
+}
+
+// Split tokenizes the input and splits it into statements on semicolons outside of parentheses
+func Split(input string, dbms sqllexer.DBMSType) []*Statement {
+	var statements []*Statement
+	statement := &Statement{}
+	// clauses holds the clause of each parentheses depth
+	clauses := []string{""}
+	position := Position{Line: 1, Column: 1}
+
+	lexer := sqllexer.New(input, sqllexer.WithDBMS(dbms))
+	for token := range lexer.All() {
+		tokenPosition := position
+		position = advance(position, token.Value)
+
+		if !isValueToken(&token) {
+			continue
+		}
+		depth := len(clauses) - 1
+		if token.Type == sqllexer.PUNCTUATION {
+			switch token.Value {
+			case ";":
+				if depth == 0 {
+					if len(statement.Tokens) > 0 {
+						statements = append(statements, statement)
+					}
+					statement = &Statement{}
+					clauses = clauses[:1]
+					clauses[0] = ""
+					continue
+				}
+			case "(":
+				statement.Tokens = append(statement.Tokens, Token{Token: token, Position: tokenPosition, Depth: depth, Clause: clauses[depth]})
+				clauses = append(clauses, clauses[depth])
+				continue
+			case ")":
+				if depth > 0 {
+					clauses = clauses[:depth]
+					depth--
+				}
+			}
+		}
+
+		if clause, ok := clauseOf(&token, statement.previous()); ok {
+			clauses[depth] = clause
+		}
+		statement.Tokens = append(statement.Tokens, Token{Token: token, Position: tokenPosition, Depth: depth, Clause: clauses[depth]})
+	}
+	if len(statement.Tokens) > 0 {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// clauseOf returns the clause started by token, if any
+func clauseOf(token *sqllexer.Token, previous *Token) (string, bool) {
+	switch token.Type {
+	case sqllexer.COMMAND:
+		value := strings.ToUpper(token.Value)
+		switch value {
+		case "JOIN", "STRAIGHT_JOIN":
+			return "FROM", true
+		case "UPDATE", "DELETE":
+			if previous != nil && (previous.Is("ON") || previous.Is("FOR") || previous.Is("KEY")) {
+				// ON UPDATE, FOR UPDATE and ON DUPLICATE KEY UPDATE
+				return "", false
+			}
+		}
+		return value, true
+	case sqllexer.KEYWORD, sqllexer.IDENT:
+		value := strings.ToUpper(token.Value)
+		switch value {
+		case "FROM", "WHERE", "HAVING", "ON", "SET", "VALUES", "LIMIT", "OFFSET", "RETURNING", "INTO", "USING", "WINDOW", "QUALIFY":
+			if token.Type == sqllexer.IDENT && value != "QUALIFY" {
+				return "", false
+			}
+			return value, true
+		case "BY":
+			if previous != nil && (previous.Is("GROUP") || previous.Is("ORDER")) {
+				return strings.ToUpper(previous.Value) + " BY", true
+			}
+		case "UNION", "INTERSECT", "EXCEPT", "MINUS":
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// previous returns the last token of the statement or nil
+func (s *Statement) previous() *Token {
+	if len(s.Tokens) == 0 {
+		return nil
+	}
+	return &s.Tokens[len(s.Tokens)-1]
+}
+
+// Closing returns the index of the parenthesis closing the one at index i, or -1
+func (s *Statement) Closing(i int) int {
+	depth := s.Tokens[i].Depth
+	for j := i + 1; j < len(s.Tokens); j++ {
+		if s.Tokens[j].Depth == depth && s.Tokens[j].Type == sqllexer.PUNCTUATION && s.Tokens[j].Value == ")" {
+			return j
+		}
+	}
+	return -1
+}
+
+// Is reports whether the token value is value, case-insensitively
+func (t *Token) Is(value string) bool {
+	return strings.EqualFold(t.Value, value)
+}
+
+func isValueToken(token *sqllexer.Token) bool {
+	switch token.Type {
+	case sqllexer.EOF, sqllexer.SPACE, sqllexer.COMMENT, sqllexer.MULTILINE_COMMENT:
+		return false
+	}
+	return true
+}
+
+// advance returns the position following value
+func advance(position Position, value string) Position {
+	position.Offset += len(value)
+	if i := strings.LastIndexByte(value, '\n'); i >= 0 {
+		position.Line += strings.Count(value, "\n")
+		position.Column = utf8.RuneCountInString(value[i+1:]) + 1
+	} else {
+		position.Column += utf8.RuneCountInString(value)
+	}
+	return position
+}
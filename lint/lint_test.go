@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/DataDog/go-sqllexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	statements := Split("SELECT a FROM t WHERE x IN (SELECT y FROM u ORDER BY y);\nDELETE FROM t;", "")
+	assert.Len(t, statements, 2)
+
+	var clauses []string
+	for _, token := range statements[0].Tokens {
+		clauses = append(clauses, token.Clause)
+	}
+	assert.Equal(t, []string{
+		"SELECT", "SELECT", "FROM", "FROM", "WHERE", "WHERE", "WHERE", "WHERE",
+		"SELECT", "SELECT", "FROM", "FROM", "FROM", "ORDER BY", "ORDER BY", "WHERE",
+	}, clauses)
+
+	deleteToken := statements[1].Tokens[0]
+	assert.Equal(t, "DELETE", deleteToken.Value)
+	assert.Equal(t, Position{Offset: 57, Line: 2, Column: 1}, deleteToken.Position)
+	assert.Equal(t, 0, deleteToken.Depth)
+}
+
+func TestPosition(t *testing.T) {
+	findings := New().Lint("SELECT id\nFROM users\nWHERE name LIKE '%é%' AND lower(email) = 'a'")
+	assert.Equal(t, []Finding{
+		{
+			Rule:     "leading-wildcard-like",
+			Severity: SeverityWarning,
+			Message:  "LIKE pattern with a leading wildcard can't use an index",
+			Position: Position{Offset: 37, Line: 3, Column: 17},
+			Value:    "'%é%'",
+		},
+		{
+			Rule:     "function-on-column",
+			Severity: SeverityWarning,
+			Message:  "function lower around column email prevents index use",
+			Position: Position{Offset: 48, Line: 3, Column: 27},
+			Value:    "lower",
+		},
+	}, findings)
+}
+
+// tableNameRule is an in-house rule reporting tables prefixed with tmp_
+type tableNameRule struct{}
+
+func (tableNameRule) Name() string { return "tmp-table" }
+
+func (r tableNameRule) Check(statement *Statement) []Finding {
+	var findings []Finding
+	for i := range statement.Tokens {
+		token := &statement.Tokens[i]
+		if token.Clause == "FROM" && token.Type == sqllexer.IDENT && len(token.Value) > 4 && token.Value[:4] == "tmp_" {
+			findings = append(findings, NewFinding(r, SeverityError, token, "temporary table in production query"))
+		}
+	}
+	return findings
+}
+
+func TestCustomRule(t *testing.T) {
+	linter := New(WithRules(append(DefaultRules(), tableNameRule{})...), WithDBMS(sqllexer.DBMSPostgres))
+	findings := linter.Lint("SELECT * FROM tmp_users")
+	assert.Len(t, findings, 2)
+	assert.Equal(t, "select-star", findings[0].Rule)
+	assert.Equal(t, "tmp-table", findings[1].Rule)
+	assert.Equal(t, "tmp_users", findings[1].Value)
+
+	linter = New(WithRules(tableNameRule{}))
+	assert.Len(t, linter.Lint("SELECT * FROM users"), 0)
+}
@@ -0,0 +1,383 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/DataDog/go-sqllexer"
+)
+
+// DefaultRules returns the built-in rules
+func DefaultRules() []Rule {
+	return []Rule{
+		SelectStar{},
+		MissingWhere{},
+		LeadingWildcardLike{},
+		NotInSubquery{},
+		ImplicitCrossJoin{},
+		OrderByRandom{},
+		FunctionOnColumn{},
+		MalformedNumber{},
+	}
+}
+
+// SelectStar reports SELECT * and SELECT t.*
+type SelectStar struct{}
+
+func (SelectStar) Name() string { return "select-star" }
+
+func (r SelectStar) Check(statement *Statement) []Finding {
+	var findings []Finding
+	for i := 1; i < len(statement.Tokens); i++ {
+		token := &statement.Tokens[i]
+		if token.Type != sqllexer.WILDCARD || token.Clause != "SELECT" {
+			continue
+		}
+		previous := &statement.Tokens[i-1]
+		if previous.Depth != token.Depth {
+			continue
+		}
+		switch {
+		case previous.Type == sqllexer.COMMAND, previous.Type == sqllexer.KEYWORD, previous.Is(","),
+			previous.Type == sqllexer.IDENT && strings.HasSuffix(previous.Value, "."):
+			findings = append(findings, NewFinding(r, SeverityWarning, token, "avoid SELECT *, list the columns the query needs"))
+		}
+	}
+	return findings
+}
+
+// MissingWhere reports UPDATE and DELETE statements without a WHERE clause
+type MissingWhere struct{}
+
+func (MissingWhere) Name() string { return "missing-where" }
+
+func (r MissingWhere) Check(statement *Statement) []Finding {
+	verb := -1
+	for i := range statement.Tokens {
+		token := &statement.Tokens[i]
+		if token.Depth != 0 {
+			continue
+		}
+		if verb < 0 && token.Type == sqllexer.COMMAND {
+			if !token.Is("UPDATE") && !token.Is("DELETE") {
+				return nil
+			}
+			verb = i
+		}
+		if verb >= 0 && token.Type == sqllexer.KEYWORD && token.Is("WHERE") {
+			return nil
+		}
+	}
+	if verb < 0 {
+		return nil
+	}
+	token := &statement.Tokens[verb]
+	return []Finding{NewFinding(r, SeverityError, token, strings.ToUpper(token.Value)+" without WHERE affects every row")}
+}
+
+// LeadingWildcardLike reports LIKE patterns starting with a wildcard, which can't use an index
+type LeadingWildcardLike struct{}
+
+func (LeadingWildcardLike) Name() string { return "leading-wildcard-like" }
+
+func (r LeadingWildcardLike) Check(statement *Statement) []Finding {
+	var findings []Finding
+	for i := 0; i+1 < len(statement.Tokens); i++ {
+		token := &statement.Tokens[i]
+		if token.Type != sqllexer.KEYWORD || (!token.Is("LIKE") && !token.Is("ILIKE")) {
+			continue
+		}
+		pattern := &statement.Tokens[i+1]
+		if (pattern.Is("N") || pattern.Is("E")) && i+2 < len(statement.Tokens) {
+			// N'...' and E'...' string prefixes
+			pattern = &statement.Tokens[i+2]
+		}
+		if pattern.Type != sqllexer.STRING {
+			continue
+		}
+		value := strings.TrimPrefix(pattern.Value, "'")
+		if strings.HasPrefix(value, "%") || strings.HasPrefix(value, "_") {
+			findings = append(findings, NewFinding(r, SeverityWarning, pattern, "LIKE pattern with a leading wildcard can't use an index"))
+		}
+	}
+	return findings
+}
+
+// NotInSubquery reports NOT IN (SELECT ...), which matches no row when the subquery returns a NULL
+type NotInSubquery struct{}
+
+func (NotInSubquery) Name() string { return "not-in-subquery" }
+
+func (r NotInSubquery) Check(statement *Statement) []Finding {
+	var findings []Finding
+	tokens := statement.Tokens
+	for i := 0; i+3 < len(tokens); i++ {
+		if tokens[i].Type == sqllexer.KEYWORD && tokens[i].Is("NOT") &&
+			tokens[i+1].Type == sqllexer.KEYWORD && tokens[i+1].Is("IN") &&
+			tokens[i+2].Is("(") &&
+			(tokens[i+3].Is("SELECT") || tokens[i+3].Type == sqllexer.CTE_INDICATOR) {
+			findings = append(findings, NewFinding(r, SeverityWarning, &tokens[i], "NOT IN (subquery) matches no row if the subquery returns NULL, use NOT EXISTS"))
+		}
+	}
+	return findings
+}
+
+// OrderByRandom reports ORDER BY RAND(), which sorts the whole result
+type OrderByRandom struct{}
+
+func (OrderByRandom) Name() string { return "order-by-random" }
+
+func (r OrderByRandom) Check(statement *Statement) []Finding {
+	var findings []Finding
+	for i := range statement.Tokens {
+		token := &statement.Tokens[i]
+		if token.Clause != "ORDER BY" {
+			continue
+		}
+		if (token.Type == sqllexer.FUNCTION && (token.Is("RAND") || token.Is("RANDOM") || token.Is("NEWID"))) ||
+			(token.Type == sqllexer.IDENT && token.Is("DBMS_RANDOM.VALUE")) {
+			findings = append(findings, NewFinding(r, SeverityWarning, token, "ORDER BY a random value sorts every row"))
+		}
+	}
+	return findings
+}
+
+// MalformedNumber reports numbers the database rejects, e.g. 1.2.3, 1e or 0x1G
+type MalformedNumber struct{}
+
+func (MalformedNumber) Name() string { return "malformed-number" }
+
+func (r MalformedNumber) Check(statement *Statement) []Finding {
+	var findings []Finding
+	for i := range statement.Tokens {
+		token := &statement.Tokens[i]
+		if token.Type == sqllexer.NUMBER && token.IsMalformed() {
+			findings = append(findings, NewFinding(r, SeverityError, token, "malformed number "+token.Value))
+		}
+	}
+	return findings
+}
+
+// FunctionOnColumn reports functions wrapped around a column compared in a WHERE clause,
+// e.g. WHERE LOWER(email) = ?, which prevent the use of an index on the column
+type FunctionOnColumn struct{}
+
+func (FunctionOnColumn) Name() string { return "function-on-column" }
+
+func (r FunctionOnColumn) Check(statement *Statement) []Finding {
+	var findings []Finding
+	tokens := statement.Tokens
+	for i := 0; i+1 < len(tokens); i++ {
+		token := &tokens[i]
+		if token.Type != sqllexer.FUNCTION || token.Clause != "WHERE" || !tokens[i+1].Is("(") {
+			continue
+		}
+		closing := statement.Closing(i + 1)
+		if closing < 0 || closing+1 >= len(tokens) || !isComparison(&tokens[closing+1]) {
+			continue
+		}
+		for j := i + 2; j < closing; j++ {
+			if tokens[j].Type == sqllexer.IDENT || tokens[j].Type == sqllexer.QUOTED_IDENT {
+				findings = append(findings, NewFinding(r, SeverityWarning, token, "function "+token.Value+" around column "+tokens[j].Value+" prevents index use"))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func isComparison(token *Token) bool {
+	switch token.Type {
+	case sqllexer.OPERATOR:
+		switch token.Value {
+		case "=", "==", "<>", "!=", "<", ">", "<=", ">=", "<=>":
+			return true
+		}
+	case sqllexer.KEYWORD:
+		return token.Is("IN") || token.Is("LIKE") || token.Is("ILIKE") || token.Is("BETWEEN") || token.Is("IS") || token.Is("NOT")
+	}
+	return false
+}
+
+// ImplicitCrossJoin reports comma separated tables that no WHERE predicate joins,
+// e.g. SELECT * FROM a, b WHERE a.x = 1
+type ImplicitCrossJoin struct{}
+
+func (ImplicitCrossJoin) Name() string { return "implicit-cross-join" }
+
+// fromItem is a table or derived table of a comma separated FROM list
+type fromItem struct {
+	names []string // table name, its last segment and its alias
+	comma int      // index of the comma preceding the item
+	bound bool     // the item can reference other items, e.g. LATERAL or a table function
+}
+
+func (r ImplicitCrossJoin) Check(statement *Statement) []Finding {
+	var findings []Finding
+	tokens := statement.Tokens
+	for i := range tokens {
+		if tokens[i].Type != sqllexer.KEYWORD || !tokens[i].Is("FROM") || tokens[i].Clause != "FROM" {
+			continue
+		}
+		items, end := fromItems(tokens, i)
+		if len(items) < 2 {
+			continue
+		}
+		joined := joinedItems(tokens, end, tokens[i].Depth, items)
+		for k := 1; k < len(items); k++ {
+			if !joined[k] {
+				findings = append(findings, NewFinding(r, SeverityWarning, &tokens[items[k].comma], "comma join without a join predicate produces a cross join"))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// fromItems reads the comma separated items of the FROM clause starting at index from.
+// It returns nil if the clause contains an explicit JOIN.
+func fromItems(tokens []Token, from int) ([]fromItem, int) {
+	depth := tokens[from].Depth
+	items := []fromItem{{comma: from}}
+	expectName := true
+	i := from + 1
+	for ; i < len(tokens); i++ {
+		token := &tokens[i]
+		if token.Depth < depth {
+			// end of the enclosing parentheses
+			return items, i
+		}
+		if token.Depth > depth {
+			continue
+		}
+		item := &items[len(items)-1]
+		switch {
+		case token.Is(","):
+			items = append(items, fromItem{comma: i})
+			expectName = true
+		case token.Type == sqllexer.COMMAND:
+			if token.Is("JOIN") || token.Is("STRAIGHT_JOIN") {
+				return nil, i
+			}
+			return items, i
+		case token.Type == sqllexer.KEYWORD && token.Is("LATERAL"):
+			item.bound = true
+		case token.Type == sqllexer.ALIAS_INDICATOR, token.Type == sqllexer.KEYWORD && token.Is("ONLY"):
+		case token.Type == sqllexer.KEYWORD:
+			if token.Is("INNER") || token.Is("LEFT") || token.Is("RIGHT") || token.Is("OUTER") || token.Is("CROSS") || token.Is("NATURAL") || token.Is("FULL") {
+				return nil, i
+			}
+			return items, i
+		case token.Type == sqllexer.FUNCTION:
+			item.bound = true
+			expectName = false
+		case token.Is("(") || token.Is(")"):
+			// derived table
+			expectName = false
+		case token.Type == sqllexer.IDENT || token.Type == sqllexer.QUOTED_IDENT:
+			name := strings.Trim(token.Value, "\"`[]")
+			if expectName {
+				item.names = append(item.names, name)
+				if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+					item.names = append(item.names, name[dot+1:])
+				}
+				expectName = false
+			} else {
+				// alias
+				item.names = append(item.names, name)
+			}
+		}
+	}
+	return items, i
+}
+
+// joinedItems reads the WHERE clause of the query block following index start
+// and reports which items are joined to the first one by a comparison of their columns.
+func joinedItems(tokens []Token, start int, depth int, items []fromItem) []bool {
+	parent := make([]int, len(items))
+	for k := range parent {
+		parent[k] = k
+	}
+	var find func(k int) int
+	find = func(k int) int {
+		for parent[k] != k {
+			k = parent[k]
+		}
+		return k
+	}
+	union := func(a, b int) {
+		parent[find(a)] = find(b)
+	}
+	joinAll := false
+
+	for k, item := range items {
+		if item.bound {
+			union(k, 0)
+		}
+	}
+
+	// subqueries opened in the WHERE clause have their own predicates
+	subqueryDepth := -1
+	for i := start; i < len(tokens); i++ {
+		token := &tokens[i]
+		if token.Depth < depth || (token.Depth == depth && token.Type == sqllexer.COMMAND) {
+			break
+		}
+		if subqueryDepth >= 0 {
+			if token.Depth == subqueryDepth && token.Is(")") {
+				subqueryDepth = -1
+			}
+			continue
+		}
+		if token.Is("(") && i+1 < len(tokens) && tokens[i+1].Type == sqllexer.COMMAND {
+			subqueryDepth = token.Depth
+			continue
+		}
+		if token.Clause != "WHERE" || token.Type != sqllexer.OPERATOR || !isComparison(token) || i == 0 || i+1 >= len(tokens) {
+			continue
+		}
+		left, right := &tokens[i-1], &tokens[i+1]
+		if !isColumn(left) || !isColumn(right) {
+			continue
+		}
+		a, b := itemOf(left.Value, items), itemOf(right.Value, items)
+		if a < 0 || b < 0 {
+			// unqualified columns, the comparison could join any item
+			if !isQualified(left.Value) || !isQualified(right.Value) {
+				joinAll = true
+			}
+			continue
+		}
+		union(a, b)
+	}
+
+	joined := make([]bool, len(items))
+	for k := range items {
+		joined[k] = joinAll || find(k) == find(0)
+	}
+	return joined
+}
+
+func isColumn(token *Token) bool {
+	return token.Type == sqllexer.IDENT || token.Type == sqllexer.QUOTED_IDENT
+}
+
+func isQualified(column string) bool {
+	return strings.IndexByte(column, '.') > 0
+}
+
+// itemOf returns the index of the item a qualified column belongs to, or -1
+func itemOf(column string, items []fromItem) int {
+	dot := strings.LastIndexByte(column, '.')
+	if dot <= 0 {
+		return -1
+	}
+	qualifier := strings.Trim(column[:dot], "\"`[]")
+	for k, item := range items {
+		for _, name := range item.names {
+			if strings.EqualFold(name, qualifier) {
+				return k
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,69 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		rule     Rule
+		input    string
+		expected []string // values of the tokens the findings are reported on
+	}{
+		{SelectStar{}, "SELECT * FROM users", []string{"*"}},
+		{SelectStar{}, "SELECT DISTINCT u.*, o.id FROM users u JOIN orders o ON o.user_id = u.id", []string{"*"}},
+		{SelectStar{}, "SELECT COUNT(*), a * b FROM users", nil},
+		{SelectStar{}, "SELECT id FROM t WHERE EXISTS (SELECT * FROM u)", []string{"*"}},
+
+		{MissingWhere{}, "DELETE FROM users", []string{"DELETE"}},
+		{MissingWhere{}, "update users set name = 'a'", []string{"update"}},
+		{MissingWhere{}, "UPDATE users SET name = 'a' WHERE id = 1", nil},
+		{MissingWhere{}, "WITH old AS (SELECT id FROM users WHERE created < now()) DELETE FROM users USING old", []string{"DELETE"}},
+		{MissingWhere{}, "DELETE FROM users WHERE id IN (SELECT id FROM banned)", nil},
+		{MissingWhere{}, "INSERT INTO t (a) VALUES (1) ON DUPLICATE KEY UPDATE a = 1", nil},
+		{MissingWhere{}, "SELECT * FROM t FOR UPDATE", nil},
+
+		{LeadingWildcardLike{}, "SELECT id FROM t WHERE name LIKE '%son' OR name NOT ILIKE '_a%' OR name LIKE 'jo%'", []string{"'%son'", "'_a%'"}},
+		{LeadingWildcardLike{}, "SELECT id FROM t WHERE name LIKE N'%son'", []string{"'%son'"}},
+
+		{NotInSubquery{}, "SELECT id FROM t WHERE id NOT IN (SELECT t_id FROM u)", []string{"NOT"}},
+		{NotInSubquery{}, "SELECT id FROM t WHERE id NOT IN (1, 2) AND id IN (SELECT t_id FROM u)", nil},
+
+		{ImplicitCrossJoin{}, "SELECT * FROM a, b", []string{","}},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, b WHERE a.x = 1", []string{","}},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, b WHERE a.id = b.a_id", nil},
+		{ImplicitCrossJoin{}, "SELECT * FROM users u, orders o, items i WHERE u.id = o.user_id", []string{","}},
+		{ImplicitCrossJoin{}, "SELECT * FROM users u, orders o, items i WHERE u.id = o.user_id AND i.order_id = o.id", nil},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, b WHERE x = y", nil},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, b WHERE a.id IN (SELECT b.id FROM b WHERE b.x = a.x)", []string{","}},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, LATERAL (SELECT * FROM b WHERE b.a_id = a.id) l", nil},
+		{ImplicitCrossJoin{}, "SELECT * FROM a, (SELECT id FROM b) sub WHERE sub.id = a.id", nil},
+		{ImplicitCrossJoin{}, "SELECT * FROM a JOIN b ON a.id = b.id", nil},
+
+		{OrderByRandom{}, "SELECT * FROM t ORDER BY RAND() LIMIT 1", []string{"RAND"}},
+		{OrderByRandom{}, "SELECT TOP 1 * FROM t ORDER BY NEWID()", []string{"NEWID"}},
+		{OrderByRandom{}, "SELECT random() FROM t ORDER BY id", nil},
+
+		{FunctionOnColumn{}, "SELECT * FROM t WHERE YEAR(created_at) = 2024 AND created_at > now()", []string{"YEAR"}},
+		{FunctionOnColumn{}, "SELECT * FROM t WHERE lower(trim(email)) LIKE 'a%'", []string{"lower"}},
+		{FunctionOnColumn{}, "SELECT lower(email) FROM t WHERE id = coalesce(lower(x), 1)", nil},
+
+		{MalformedNumber{}, "SELECT * FROM t WHERE a = 1.2.3 OR b = 1e OR c = 0x1G OR d = 12abc", []string{"1.2.3", "1e", "0x1G", "12abc"}},
+		{MalformedNumber{}, "SELECT * FROM t WHERE a = 1.5e-3 OR b = 0x1F OR c = 0b1010 OR d = 0o17 OR e = .5", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule.Name()+": "+tt.input, func(t *testing.T) {
+			var values []string
+			for _, statement := range Split(tt.input, "") {
+				for _, finding := range tt.rule.Check(statement) {
+					assert.Equal(t, tt.rule.Name(), finding.Rule)
+					values = append(values, finding.Value)
+				}
+			}
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
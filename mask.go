@@ -0,0 +1,80 @@
+package sqllexer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// maskedStringLength is the number of hex characters of the HMAC in a masked string
+const maskedStringLength = 6
+
+// maskString returns the masked string literal 'h:<hmac>' of a string token.
+// The HMAC is computed on the content of the string, so 'bob', N'bob' and $$bob$$ mask the same way.
+func maskString(key []byte, token *Token) string {
+	var value string
+	switch token.Type {
+	case DOLLAR_QUOTED_STRING:
+		tag := strings.IndexByte(token.Value[1:], '$') + 2
+		value = token.Value[tag : len(token.Value)-tag]
+	default:
+		value = unquoteString(token, false)
+	}
+	sum := maskSum(key, value)
+
+	var builder strings.Builder
+	builder.Grow(maskedStringLength + 4)
+	builder.WriteString("'h:")
+	builder.WriteString(hex.EncodeToString(sum[:maskedStringLength/2]))
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+// maskNumber returns a number of the same length as the number literal, replacing its digits
+// with digits derived from the HMAC of the value. Signs, decimal points, exponents and radix prefixes
+// such as 0x and b' are kept, hex and binary digits stay hex and binary, and a leading digit is never replaced by 0.
+func maskNumber(key []byte, value string) string {
+	sum := maskSum(key, value)
+	masked := []byte(value)
+	radix, start := numberRadix(value)
+	if radix != 0 {
+		digits := "0123456789abcdef"
+		switch radix {
+		case 'b':
+			digits = "01"
+		case 'o':
+			digits = "01234567"
+		}
+		for i := start; i < len(masked); i++ {
+			if strings.IndexByte(digits, masked[i]|0x20) >= 0 || isDigit(rune(masked[i])) {
+				masked[i] = digits[sum[i%len(sum)]%byte(len(digits))]
+			}
+		}
+		return string(masked)
+	}
+	// leading is true when the next digit starts the number or its exponent
+	leading := true
+	for i := 0; i < len(masked); i++ {
+		b := sum[i%len(sum)]
+		switch {
+		case isDigit(rune(masked[i])):
+			if leading && i+1 < len(masked) && isDigit(rune(masked[i+1])) {
+				// 0 would make 42 the octal 07
+				masked[i] = '1' + b%9
+			} else {
+				masked[i] = '0' + b%10
+			}
+			leading = false
+		default:
+			leading = isExpontent(rune(masked[i])) || isLeadingSign(rune(masked[i])) && leading
+		}
+	}
+	return string(masked)
+}
+
+func maskSum(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
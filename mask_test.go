@@ -0,0 +1,78 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscatorMaskingKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		dbms     DBMSType
+	}{
+		{
+			input:    "SELECT * FROM users WHERE name = 'bob' AND id = 42",
+			expected: "SELECT * FROM users WHERE name = 'h:9c9081' AND id = 43",
+		},
+		{
+			// the content of the string is masked, whatever the quoting
+			input:    "SELECT * FROM users WHERE name = N'bob' OR name = $$bob$$",
+			expected: "SELECT * FROM users WHERE name = N'h:9c9081' OR name = 'h:9c9081'",
+			dbms:     DBMSPostgres,
+		},
+		{
+			input:    "SELECT * FROM users WHERE name IN ('bob', 'alice')",
+			expected: "SELECT * FROM users WHERE name IN ('h:9c9081', 'h:4360c6')",
+		},
+		{
+			// numbers keep their sign, decimal point, exponent and hex prefix
+			input:    "SELECT * FROM t WHERE a = -1.5e3 AND b = 0x1F AND c = 0 AND d = 1e-10",
+			expected: "SELECT * FROM t WHERE a = -9.8e0 AND b = 0xe8 AND c = 3 AND d = 6e-65",
+		},
+		{
+			input:    "SELECT * FROM users WHERE id = $1 AND active = TRUE",
+			expected: "SELECT * FROM users WHERE id = $1 AND active = ?",
+			dbms:     DBMSPostgres,
+		},
+	}
+
+	obfuscator := NewObfuscator(
+		WithReplaceBoolean(true),
+		WithMaskingKey([]byte("secret")),
+	)
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got := obfuscator.Obfuscate(tt.input, WithDBMS(tt.dbms))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestMaskNumber(t *testing.T) {
+	key := []byte("secret")
+	for _, value := range []string{"1", "42", "123456789", "-7", "3.14", "1e10", "0x1F"} {
+		t.Run(value, func(t *testing.T) {
+			masked := maskNumber(key, value)
+			assert.Len(t, masked, len(value))
+			assert.Equal(t, masked, maskNumber(key, value), "masking is deterministic")
+			assert.NotEqual(t, masked, maskNumber([]byte("other"), value), "masking depends on the key")
+			lexer := New(masked)
+			token := lexer.Scan()
+			assert.Equal(t, NUMBER, token.Type)
+			assert.Equal(t, masked, token.Value)
+		})
+	}
+}
+
+func TestObfuscateAndNormalizeMaskingKey(t *testing.T) {
+	obfuscator := NewObfuscator(WithMaskingKey([]byte("secret")))
+	normalizer := NewNormalizer()
+
+	got, _, err := ObfuscateAndNormalize("SELECT * FROM users WHERE name IN ('bob', 'alice') AND id = 42", obfuscator, normalizer)
+	assert.NoError(t, err)
+	// masked values are not grouped, to keep the values apart
+	assert.Equal(t, "SELECT * FROM users WHERE name IN ( 'h:9c9081', 'h:4360c6' ) AND id = 43", got)
+}
@@ -0,0 +1,77 @@
+package sqllexer
+
+import "strings"
+
+// Minify removes comments and whitespace from the input, keeping literals, aliases and quoting as they are.
+// A space is only kept between two tokens that would otherwise merge into a different token,
+// e.g. SELECT a, or - -1 which would become a comment.
+// Optimizer hints (/*+ ... */, --+ ...) and MySQL executable comments (/*! ... */) are kept, as they change the query.
+func Minify(input string, lexerOpts ...lexerOption) string {
+	var builder strings.Builder
+	builder.Grow(len(input))
+
+	var previous string
+	// separated is true when the previous written token was followed by whitespace or a comment in the input
+	separated := false
+	// newline is true when the previous written token is a single line comment
+	newline := false
+	lexer := New(input, lexerOpts...)
+	for token := range lexer.All() {
+		switch token.Type {
+		case SPACE:
+			separated = true
+			continue
+		case COMMENT, MULTILINE_COMMENT:
+			if !isExecutableComment(token.Value) {
+				separated = true
+				continue
+			}
+		}
+
+		switch {
+		case newline:
+			builder.WriteByte('\n')
+		case separated && previous != "" && tokensMerge(previous, token.Value, lexerOpts...):
+			builder.WriteByte(' ')
+		}
+		value := token.Value
+		if token.Type == COMMENT {
+			value = strings.TrimRight(value, "\r\n")
+		}
+		builder.WriteString(value)
+		previous = value
+		separated = false
+		newline = token.Type == COMMENT
+	}
+	return builder.String()
+}
+
+// isExecutableComment reports whether the comment changes the query and must be kept
+func isExecutableComment(comment string) bool {
+	return strings.HasPrefix(comment, "/*+") || strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "--+")
+}
+
+// tokensMerge reports whether left and right must be separated by a space:
+// when both sides are words or quoted, since e.g. MySQL identifiers may start with digits, n 'x' would become N'x'
+// and `a` `b` would become the escaped `a“b`, or when they are not lexed as the same two tokens once concatenated
+func tokensMerge(left string, right string, lexerOpts ...lexerOption) bool {
+	last, first := left[len(left)-1], right[0]
+	if (isWordByte(last) || isQuoteByte(last) || last == ']') && (isWordByte(first) || isQuoteByte(first) || first == '[') {
+		return true
+	}
+	lexer := New(left+right, lexerOpts...)
+	token := lexer.Scan()
+	if token.Type == EOF || token.Value != left {
+		return true
+	}
+	token = lexer.Scan()
+	return token.Type == EOF || token.Value != right
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '$' || b == '@' || b == '#' || b >= 0x80
+}
+
+func isQuoteByte(b byte) bool {
+	return b == '\'' || b == '"' || b == '`'
+}
@@ -0,0 +1,91 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		dbms     DBMSType
+		expected string
+	}{
+		{
+			name:     "whitespace",
+			input:    "SELECT  a ,\n\tb\n  FROM   t\n WHERE a = 1",
+			expected: "SELECT a,b FROM t WHERE a=1",
+		},
+		{
+			name:     "comments",
+			input:    "SELECT a -- the a column\nFROM t /* the table */ WHERE b = 2 # trailing",
+			dbms:     DBMSMySQL,
+			expected: "SELECT a FROM t WHERE b=2",
+		},
+		{
+			name:     "literals aliases and quoting are kept",
+			input:    "SELECT a AS \"My Col\", [b] AS c, 'a  b' AS d FROM \"Users\" u WHERE e = 1.50",
+			expected: "SELECT a AS \"My Col\",[b] AS c,'a  b' AS d FROM \"Users\" u WHERE e=1.50",
+		},
+		{
+			name:     "negative numbers",
+			input:    "SELECT a - -1, b - - c, c = -1 FROM t",
+			expected: "SELECT a- -1,b- -c,c= -1 FROM t",
+		},
+		{
+			// x/2 is lexed as a single identifier
+			name:     "comment removed between operators",
+			input:    "SELECT x - /* minus */ -y, x / /* divide */ 2 FROM t",
+			expected: "SELECT x- -y,x /2 FROM t",
+		},
+		{
+			name:     "string prefixes",
+			input:    "SELECT n 'x', N'y', e'z' FROM t",
+			expected: "SELECT n 'x',N'y',e'z' FROM t",
+		},
+		{
+			name:     "doubled quotes",
+			input:    "SELECT `a` `b`, 'c' 'd' FROM t",
+			dbms:     DBMSMySQL,
+			expected: "SELECT `a` `b`,'c' 'd' FROM t",
+		},
+		{
+			name:     "hints are kept",
+			input:    "SELECT /*+ INDEX(t i) */ * FROM t /*!50000 FORCE INDEX (i) */ WHERE a = $1",
+			expected: "SELECT/*+ INDEX(t i) */*FROM t /*!50000 FORCE INDEX (i) */WHERE a=$1",
+		},
+		{
+			name:     "single line hint ends its line",
+			input:    "SELECT --+ FULL(t)\n * FROM t",
+			dbms:     DBMSOracle,
+			expected: "SELECT--+ FULL(t)\n*FROM t",
+		},
+		{
+			name:     "dollar quoted string",
+			input:    "SELECT $$ a  b $$ , $tag$ c $tag$",
+			dbms:     DBMSPostgres,
+			expected: "SELECT $$ a  b $$,$tag$ c $tag$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Minify(tt.input, WithDBMS(tt.dbms)))
+		})
+	}
+}
+
+func TestMinifyKeepsTokens(t *testing.T) {
+	input := "SELECT a - -1, 'x' AS y, \"Z\" FROM t -- c\nWHERE b <> :b AND c::text = $1"
+	expected := []string{}
+	for token := range New(input).ValueTokens() {
+		expected = append(expected, token.Value)
+	}
+	actual := []string{}
+	for token := range New(Minify(input)).ValueTokens() {
+		actual = append(actual, token.Value)
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -2,6 +2,8 @@ package sqllexer
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -15,6 +17,50 @@ type normalizerConfig struct {
 	// CollectComments specifies whether the normalizer should extract and return comments as SQL metadata
 	CollectComments bool `json:"collect_comments"`
 
+	// ParseComments specifies whether the normalizer should parse sqlcommenter and marginalia
+	// key-value comments into CommentTags, and W3C trace context into TraceContext
+	ParseComments bool `json:"parse_comments"`
+
+	// KeepHints specifies whether optimizer hint comments (e.g. /*+ INDEX(t idx) */) should be kept in the normalized SQL.
+	// Comments are removed from the normalized SQL by default.
+	KeepHints bool `json:"keep_hints"`
+
+	// KeepCommentPrefixes specifies the prefixes of comments that should be kept in the normalized SQL.
+	// A prefix is matched against the whole comment, including its delimiter (e.g. "/* keep").
+	KeepCommentPrefixes []string `json:"keep_comment_prefixes"`
+
+	// KeepCommentPatterns specifies regular expressions matching comments that should be kept in the normalized SQL.
+	KeepCommentPatterns []*regexp.Regexp `json:"-"`
+
+	// SecretScanner redacts the secrets of the comments collected or kept by the normalizer
+	SecretScanner *SecretScanner `json:"-"`
+
+	// CollectHints specifies whether the normalizer should extract and return optimizer hints as SQL metadata
+	CollectHints bool `json:"collect_hints"`
+
+	// CollectIndexes specifies whether the normalizer should extract and return index names as SQL metadata
+	CollectIndexes bool `json:"collect_indexes"`
+
+	// ClassifyStatement specifies whether the normalizer should classify the statement
+	// (type, category and read-only) as SQL metadata
+	ClassifyStatement bool `json:"classify_statement"`
+
+	// CollectScopes specifies whether the normalizer should extract and return the scope tree of subqueries
+	// and CTEs, with the tables read in each scope, as SQL metadata
+	CollectScopes bool `json:"collect_scopes"`
+
+	// CollectFunctions specifies whether the normalizer should extract and return the called functions,
+	// classified as aggregate, window, table-valued or scalar, as SQL metadata
+	CollectFunctions bool `json:"collect_functions"`
+
+	// CollectComplexity specifies whether the normalizer should compute structural statistics
+	// of the query (joins, subquery depth, predicates...) as SQL metadata
+	CollectComplexity bool `json:"collect_complexity"`
+
+	// CollectLiteralTypes specifies whether the normalizer should extract and return the types of typed string
+	// literals, such as date for DATE '2024-01-01' or jsonb for '{}'::jsonb, as SQL metadata
+	CollectLiteralTypes bool `json:"collect_literal_types"`
+
 	// CollectProcedure specifies whether the normalizer should extract and return procedure name as SQL metadata
 	CollectProcedure bool `json:"collect_procedure"`
 
@@ -35,6 +81,17 @@ type normalizerConfig struct {
 
 	// KeepIdentifierQuotation specifies whether the normalizer should keep the quotation of identifiers.
 	KeepIdentifierQuotation bool `json:"keep_identifier_quotation"`
+
+	// CollapseRepeatedTuples specifies whether the repeated tuples of a list, such as the rows of a multi-row
+	// VALUES or the ROW constructors of an array, should be collapsed into the first one,
+	// so that multi-row INSERTs of any size share the same normalized SQL.
+	CollapseRepeatedTuples bool `json:"collapse_repeated_tuples"`
+
+	// GroupBindParameters specifies whether runs of positional and bind parameters of the input,
+	// such as IN ($1, $2, $3) or IN (:1, :2), should be grouped like obfuscated values, e.g. IN ( $1 ),
+	// so that lists of any size share the same normalized SQL without replacing the parameters.
+	// Numbered parameters are then renumbered in order, e.g. IN ($1, $2) AND b = $3 is normalized as IN ( $1 ) AND b = $2.
+	GroupBindParameters bool `json:"group_bind_parameters"`
 }
 
 type normalizerOption func(*normalizerConfig)
@@ -57,6 +114,80 @@ func WithCollectComments(collectComments bool) normalizerOption {
 	}
 }
 
+func WithParseComments(parseComments bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.ParseComments = parseComments
+	}
+}
+
+func WithKeepHints(keepHints bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.KeepHints = keepHints
+	}
+}
+
+func WithKeepCommentPrefixes(prefixes ...string) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.KeepCommentPrefixes = prefixes
+	}
+}
+
+func WithKeepCommentPatterns(patterns ...*regexp.Regexp) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.KeepCommentPatterns = patterns
+	}
+}
+
+// WithCommentSecretScanner redacts the secrets found by the scanner in the comments collected or kept
+// by the normalizer, and reports the rules that fired in StatementMetadata.Secrets
+func WithCommentSecretScanner(secretScanner *SecretScanner) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.SecretScanner = secretScanner
+	}
+}
+
+func WithCollectHints(collectHints bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectHints = collectHints
+	}
+}
+
+func WithCollectIndexes(collectIndexes bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectIndexes = collectIndexes
+	}
+}
+
+func WithClassifyStatement(classifyStatement bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.ClassifyStatement = classifyStatement
+	}
+}
+
+func WithCollectScopes(collectScopes bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectScopes = collectScopes
+	}
+}
+
+func WithCollectFunctions(collectFunctions bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectFunctions = collectFunctions
+	}
+}
+
+func WithCollectComplexity(collectComplexity bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectComplexity = collectComplexity
+	}
+}
+
+func WithCollectLiteralTypes(collectLiteralTypes bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectLiteralTypes = collectLiteralTypes
+	}
+}
+
 func WithKeepSQLAlias(keepSQLAlias bool) normalizerOption {
 	return func(c *normalizerConfig) {
 		c.KeepSQLAlias = keepSQLAlias
@@ -93,20 +224,68 @@ func WithKeepIdentifierQuotation(keepIdentifierQuotation bool) normalizerOption
 	}
 }
 
+func WithCollapseRepeatedTuples(collapseRepeatedTuples bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollapseRepeatedTuples = collapseRepeatedTuples
+	}
+}
+
+func WithGroupBindParameters(groupBindParameters bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.GroupBindParameters = groupBindParameters
+	}
+}
+
 type StatementMetadata struct {
-	Size       int      `json:"size"`
-	Tables     []string `json:"tables"`
-	Comments   []string `json:"comments"`
-	Commands   []string `json:"commands"`
-	Procedures []string `json:"procedures"`
+	Size         int               `json:"size"`
+	Tables       []string          `json:"tables"`
+	Comments     []string          `json:"comments"`
+	Commands     []string          `json:"commands"`
+	Procedures   []string          `json:"procedures"`
+	CommentTags  map[string]string `json:"comment_tags,omitempty"`
+	TraceContext *TraceContext     `json:"trace_context,omitempty"`
+	Hints        []Hint            `json:"hints,omitempty"`
+	Indexes      []string          `json:"indexes,omitempty"`
+	// StatementType is the leading verb of the first statement, e.g. SELECT, INSERT or CREATE
+	StatementType string            `json:"statement_type,omitempty"`
+	Category      StatementCategory `json:"category,omitempty"`
+	// IsReadOnly is true when every statement only reads data and can be routed to a replica
+	IsReadOnly bool `json:"is_read_only,omitempty"`
+	// Scopes holds the scope tree of each statement
+	Scopes    []*Scope   `json:"scopes,omitempty"`
+	Functions []Function `json:"functions,omitempty"`
+	// Complexity is only set when complexity collection is enabled
+	Complexity *Complexity `json:"complexity,omitempty"`
+	// LiteralTypes holds the types of the typed string literals, e.g. date, interval or jsonb
+	LiteralTypes []string `json:"literal_types,omitempty"`
+	// Secrets holds the names of the secret rules that fired, see WithSecretScanner and WithCommentSecretScanner
+	Secrets []string `json:"secrets,omitempty"`
+	// HasCredentials is true when the query holds a credential clause, such as PASSWORD 'x' or IDENTIFIED BY 'x',
+	// whose secret is redacted by ObfuscateAndNormalize with WithScrubCredentials
+	HasCredentials bool `json:"has_credentials,omitempty"`
 }
 
 type metadataSet struct {
-	size          int
+	size       int
+	hints      hintCollector
+	indexes    indexCollector
+	classifier statementClassifier
+	scopes     scopeStack
+	functions  functionCollector
+	complexity complexityCollector
+}
+
+// metadataSets holds the sets deduplicating the collected metadata. They are kept apart from metadataSet,
+// whose collectors hand their results over to the statement metadata, so that they can stay on the stack.
+type metadataSets struct {
 	tablesSet     map[string]struct{}
 	commentsSet   map[string]struct{}
 	commandsSet   map[string]struct{}
 	proceduresSet map[string]struct{}
+	hintsSet      map[Hint]struct{}
+	indexesSet    map[string]struct{}
+	functionsSet  map[Function]struct{}
+	literalsSet   map[string]struct{}
 }
 
 // addMetadata adds a value to a metadata slice if it doesn't exist in the set.
@@ -159,7 +338,7 @@ func NewNormalizer(opts ...normalizerOption) *Normalizer {
 }
 
 // normalizeToken is a helper function that handles the common normalization logic
-func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.Builder, meta *metadataSet, statementMetadata *StatementMetadata, preProcessToken func(*Token, *LastValueToken), lexerOpts ...lexerOption) (err error) {
+func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.Builder, meta *metadataSet, sets *metadataSets, statementMetadata *StatementMetadata, preProcessToken func(*Token, *LastValueToken), preProcessCommentToken func(*Token, *LastValueToken), placeholders placeholderContext, lexerOpts ...lexerOption) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("error normalizing SQL token: %v", r)
@@ -169,8 +348,14 @@ func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.
 	var groupablePlaceholder groupablePlaceholder
 	var headState headState
 	var colonCtx colonContext
-	var ctes map[string]bool // Lazily initialized when first CTE is encountered
-	var inTableList bool
+	if n.config.GroupBindParameters {
+		// the parameters of the input are renumbered along with the placeholders
+		placeholders.position = 0
+	}
+	var tuples tupleContext
+	if n.config.CollapseRepeatedTuples {
+		tuples = newTupleContext(lexerOpts...)
+	}
 
 	var lastValueToken *LastValueToken
 
@@ -178,12 +363,35 @@ func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.
 		token := lexer.Scan()
 		if preProcessToken != nil {
 			// pre-process the token, often used for obfuscation
+			value := token.Value
 			preProcessToken(token, lastValueToken)
+			if n.config.CollectComplexity && token.Value != value && isLiteralToken(token) {
+				meta.complexity.complexity.ObfuscatedLiterals++
+			}
+		}
+		if n.config.SecretScanner != nil && (token.Type == COMMENT || token.Type == MULTILINE_COMMENT) {
+			for _, rule := range n.config.SecretScanner.redactToken(token) {
+				if !slices.Contains(statementMetadata.Secrets, rule) {
+					statementMetadata.Secrets = append(statementMetadata.Secrets, rule)
+				}
+			}
 		}
 		if n.shouldCollectMetadata() {
-			n.collectMetadata(token, lastValueToken, meta, statementMetadata, &ctes, &inTableList)
+			n.collectMetadata(token, lastValueToken, meta, sets, statementMetadata)
+		}
+		if preProcessCommentToken != nil && n.shouldKeepComment(token) {
+			// kept comments such as optimizer hints can carry literals, obfuscate them as well
+			token.Value = obfuscateComment(token.Value, preProcessCommentToken, lexerOpts...)
+		}
+		if n.config.CollapseRepeatedTuples && !headState.inLeadingParenthesesExpression {
+			builder, skip := tuples.open(token, lastValueToken, normalizedSQLBuilder, &placeholders)
+			if !skip {
+				n.normalizeSQL(token, lastValueToken, builder, &groupablePlaceholder, &headState, &colonCtx, &placeholders, lexerOpts...)
+				tuples.close(token, normalizedSQLBuilder, &placeholders)
+			}
+		} else {
+			n.normalizeSQL(token, lastValueToken, normalizedSQLBuilder, &groupablePlaceholder, &headState, &colonCtx, &placeholders, lexerOpts...)
 		}
-		n.normalizeSQL(token, lastValueToken, normalizedSQLBuilder, &groupablePlaceholder, &headState, &colonCtx, lexerOpts...)
 		if token.Type == EOF {
 			break
 		}
@@ -196,22 +404,43 @@ func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.
 }
 
 func (n *Normalizer) Normalize(input string, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
-	return n.normalize(input, nil, lexerOpts...)
+	return n.normalize(input, nil, nil, placeholderContext{}, lexerOpts...)
 }
 
 // normalize is the internal implementation that handles the common normalization logic.
 // preProcessToken is an optional function to process tokens before normalization (e.g., obfuscation).
-func (n *Normalizer) normalize(input string, preProcessToken func(*Token, *LastValueToken), lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+// preProcessCommentToken is an optional function to process the tokens of kept comments.
+// placeholders numbers the placeholders written by preProcessToken once they are grouped.
+func (n *Normalizer) normalize(input string, preProcessToken func(*Token, *LastValueToken), preProcessCommentToken func(*Token, *LastValueToken), placeholders placeholderContext, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+	lexerOpts = withTypedLiterals(lexerOpts, n.config.CollectLiteralTypes)
 	lexer := New(input, lexerOpts...)
 	var normalizedSQLBuilder strings.Builder
 	normalizedSQLBuilder.Grow(len(input))
 
 	meta := &metadataSet{
+		indexes:   indexCollector{dbms: lexer.config.DBMS},
+		scopes:    scopeStack{collect: n.config.CollectScopes, nested: n.config.CollectScopes || n.config.CollectComplexity || n.config.CollectFunctions},
+		functions: functionCollector{closed: -1},
+	}
+	sets := &metadataSets{
 		tablesSet:     map[string]struct{}{},
 		commentsSet:   map[string]struct{}{},
 		commandsSet:   map[string]struct{}{},
 		proceduresSet: map[string]struct{}{},
 	}
+	// the sets of optional metadata are only allocated when collected
+	if n.config.CollectHints {
+		sets.hintsSet = map[Hint]struct{}{}
+	}
+	if n.config.CollectIndexes {
+		sets.indexesSet = map[string]struct{}{}
+	}
+	if n.config.CollectFunctions {
+		sets.functionsSet = map[Function]struct{}{}
+	}
+	if n.config.CollectLiteralTypes {
+		sets.literalsSet = map[string]struct{}{}
+	}
 
 	statementMetadata = &StatementMetadata{
 		Tables:     []string{},
@@ -220,31 +449,80 @@ func (n *Normalizer) normalize(input string, preProcessToken func(*Token, *LastV
 		Procedures: []string{},
 	}
 
-	if err = n.normalizeToken(lexer, &normalizedSQLBuilder, meta, statementMetadata, preProcessToken, lexerOpts...); err != nil {
+	if err = n.normalizeToken(lexer, &normalizedSQLBuilder, meta, sets, statementMetadata, preProcessToken, preProcessCommentToken, placeholders, lexerOpts...); err != nil {
 		return "", nil, err
 	}
 
+	if n.config.ClassifyStatement {
+		meta.classifier.apply(statementMetadata)
+	}
+
+	if n.config.CollectFunctions {
+		meta.addFunctions(sets.functionsSet, statementMetadata)
+	}
+	if n.config.CollectComplexity {
+		meta.complexity.apply(statementMetadata, meta.scopes.ctes)
+	}
+	if n.config.CollectScopes {
+		statementMetadata.Scopes = meta.scopes.roots
+		meta.size += meta.scopes.size
+	}
+
 	normalizedSQL = strings.Clone(normalizedSQLBuilder.String())
 	statementMetadata.Size = meta.size
 	return n.trimNormalizedSQL(normalizedSQL), statementMetadata, nil
 }
 
 func (n *Normalizer) shouldCollectMetadata() bool {
-	return n.config.CollectTables || n.config.CollectCommands || n.config.CollectComments || n.config.CollectProcedure
+	return n.config.CollectTables || n.config.CollectCommands || n.config.CollectComments || n.config.CollectProcedure || n.config.ParseComments || n.config.CollectHints || n.config.CollectIndexes || n.config.ClassifyStatement || n.config.CollectScopes || n.config.CollectFunctions || n.config.CollectComplexity || n.config.CollectLiteralTypes
 }
 
-func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToken, meta *metadataSet, statementMetadata *StatementMetadata, ctes *map[string]bool, inTableList *bool) {
-	if n.config.CollectComments && (token.Type == COMMENT || token.Type == MULTILINE_COMMENT) {
+func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToken, meta *metadataSet, sets *metadataSets, statementMetadata *StatementMetadata) {
+	var indexRole indexRole
+	var isCTEName bool
+	if isValueToken(token) {
+		if n.config.CollectIndexes || n.config.CollectFunctions {
+			indexRole = meta.indexes.role(token)
+		}
+		inTableList := meta.scopes.inTableList
+		if n.config.CollectComplexity {
+			meta.complexity.collect(token, lastValueToken, inTableList)
+		}
+		if meta.scopes.nested {
+			isCTEName = meta.scopes.read(token)
+		} else if token.Type == PUNCTUATION && (token.Value == "(" || token.Value == ")") {
+			// without nesting, parentheses end table lists
+			meta.scopes.inTableList = false
+		} else if lastValueToken != nil && lastValueToken.Type == CTE_INDICATOR {
+			isCTEName = meta.scopes.readFlat(token)
+		}
+		if n.config.CollectFunctions {
+			var call, tableValued bool
+			if token.Type == FUNCTION && indexRole == indexRoleNone && !isCTEName {
+				call, tableValued = isFunctionCall(lastValueToken, inTableList)
+			}
+			meta.functions.collect(token, call, tableValued)
+		}
+	}
+
+	if token.Type == COMMENT || token.Type == MULTILINE_COMMENT {
 		comment := token.Value
-		meta.addMetadata(comment, meta.commentsSet, &statementMetadata.Comments)
+		if n.config.CollectComments {
+			meta.addMetadata(comment, sets.commentsSet, &statementMetadata.Comments)
+		}
+		if n.config.ParseComments {
+			meta.collectCommentTags(comment, statementMetadata)
+		}
+	} else if token.Type == STRING || token.Type == INCOMPLETE_STRING {
+		if n.config.CollectLiteralTypes && token.LiteralType() != "" {
+			meta.addMetadata(token.LiteralType(), sets.literalsSet, &statementMetadata.LiteralTypes)
+		}
 	} else if token.Type == COMMAND || token.Type == KEYWORD {
-		*inTableList = false
+		meta.scopes.inTableList = false
 		if n.config.CollectCommands && token.Type == COMMAND {
 			command := strings.ToUpper(token.Value)
-			meta.addMetadata(command, meta.commandsSet, &statementMetadata.Commands)
+			meta.addMetadata(command, sets.commandsSet, &statementMetadata.Commands)
 		}
-	} else if token.Type == PUNCTUATION && (token.Value == "(" || token.Value == ")") {
-		*inTableList = false
 	} else if token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION {
 		tokenVal := token.Value
 		if token.Type == QUOTED_IDENT {
@@ -256,34 +534,69 @@ func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToke
 			}
 		}
 
-		// Only collect metadata if we have context from the previous token
-		if lastValueToken != nil {
-			// Track CTE names so we can exclude them from the tables list
-			if lastValueToken.Type == CTE_INDICATOR {
-				if *ctes == nil {
-					*ctes = make(map[string]bool, 2)
-				}
-				(*ctes)[tokenVal] = true
-			} else if n.config.CollectTables && lastValueToken.isTableIndicator {
-				*inTableList = true
-				isCTE := *ctes != nil && (*ctes)[tokenVal]
-				if !isCTE {
-					meta.addMetadata(tokenVal, meta.tablesSet, &statementMetadata.Tables)
-				}
-			} else if n.config.CollectTables && *inTableList && lastValueToken.Type == PUNCTUATION && lastValueToken.Value == "," {
-				isCTE := *ctes != nil && (*ctes)[tokenVal]
-				if !isCTE {
-					meta.addMetadata(tokenVal, meta.tablesSet, &statementMetadata.Tables)
+		if indexRole == indexRoleName {
+			if n.config.CollectIndexes {
+				meta.addMetadata(tokenVal, sets.indexesSet, &statementMetadata.Indexes)
+			}
+		} else if indexRole == indexRoleTable {
+			meta.hints.lastTable = tokenVal
+			if n.config.CollectTables {
+				meta.addMetadata(tokenVal, sets.tablesSet, &statementMetadata.Tables)
+			}
+			meta.scopes.addTable(tokenVal)
+		} else if lastValueToken != nil && !isCTEName {
+			// Only collect metadata if we have context from the previous token
+			if lastValueToken.isTableIndicator || (meta.scopes.inTableList && lastValueToken.Type == PUNCTUATION && lastValueToken.Value == ",") {
+				meta.scopes.inTableList = true
+				// CTE names are excluded from the tables list where the CTE is visible
+				if meta.scopes.isCTE(tokenVal) {
+					meta.scopes.addCTE(tokenVal)
+				} else {
+					// CockroachDB references an index as table@idx
+					table, index := meta.indexes.splitTableIndex(tokenVal)
+					// remember the table so hints such as WITH (NOLOCK) can refer to it
+					meta.hints.lastTable = table
+					if n.config.CollectTables {
+						meta.addMetadata(table, sets.tablesSet, &statementMetadata.Tables)
+					}
+					meta.scopes.addTable(table)
+					if n.config.CollectIndexes && index != "" {
+						meta.addMetadata(index, sets.indexesSet, &statementMetadata.Indexes)
+					}
 				}
 			} else if n.config.CollectProcedure && lastValueToken.Type == PROC_INDICATOR {
 				// Collect procedure names
-				meta.addMetadata(tokenVal, meta.proceduresSet, &statementMetadata.Procedures)
+				meta.addMetadata(tokenVal, sets.proceduresSet, &statementMetadata.Procedures)
+			}
+		}
+	}
+
+	if n.config.ClassifyStatement {
+		meta.classifier.classify(token)
+	}
+
+	if n.config.CollectHints || n.config.CollectIndexes {
+		for _, hint := range meta.hints.collect(token) {
+			if n.config.CollectHints {
+				meta.addHint(hint, sets.hintsSet, statementMetadata)
+			}
+			if n.config.CollectIndexes && hint.Index != "" {
+				meta.addMetadata(hint.Index, sets.indexesSet, &statementMetadata.Indexes)
 			}
 		}
 	}
 }
 
-func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder *strings.Builder, groupablePlaceholder *groupablePlaceholder, headState *headState, colonCtx *colonContext, lexerOpts ...lexerOption) {
+func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder *strings.Builder, groupablePlaceholder *groupablePlaceholder, headState *headState, colonCtx *colonContext, placeholders *placeholderContext, lexerOpts ...lexerOption) {
+	if n.shouldKeepComment(token) {
+		builder := normalizedSQLBuilder
+		if headState.inLeadingParenthesesExpression {
+			builder = &headState.expressionInParentheses
+		}
+		n.appendSpace(token, lastValueToken, builder, colonCtx)
+		builder.WriteString(toMultiLineComment(token.Value))
+		return
+	}
 	if token.Type != SPACE && token.Type != COMMENT && token.Type != MULTILINE_COMMENT {
 		if token.Type == QUOTED_IDENT && !n.config.KeepIdentifierQuotation {
 			if n.shouldStripIdentifierQuotes(token, lastValueToken) {
@@ -319,7 +632,7 @@ func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken,
 			headState.foundLeadingExpressionInParentheses = false
 		}
 
-		if token.Type == DOLLAR_QUOTED_FUNCTION && token.Value != StringPlaceholder {
+		if token.Type == DOLLAR_QUOTED_FUNCTION && !isObfuscatedPlaceholder(token.Type, token.Value) {
 			// if the token is a dollar quoted function and it is not obfuscated,
 			// we need to recusively normalize the content of the dollar quoted function
 			quotedFunc := token.Value[6 : len(token.Value)-6] // remove the $func$ prefix and suffix
@@ -360,6 +673,11 @@ func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken,
 			// return the token but not write it to the normalizedSQLBuilder
 			return
 		}
+		if n.config.GroupBindParameters {
+			// number the parameters once grouped, e.g. IN ($1, $2) AND b = $3 is normalized as IN ( $1 ) AND b = $2
+			placeholders.renumber(token)
+		}
+		placeholders.number(token)
 
 		if headState.inLeadingParenthesesExpression {
 			n.appendSpace(token, lastValueToken, &headState.expressionInParentheses, colonCtx)
@@ -398,6 +716,29 @@ func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken,
 	}
 }
 
+// shouldKeepComment reports whether a comment token should be written to the normalized SQL
+func (n *Normalizer) shouldKeepComment(token *Token) bool {
+	return (token.Type == COMMENT || token.Type == MULTILINE_COMMENT) && n.isKeptComment(token)
+}
+
+// isKeptComment reports whether the comment is an optimizer hint or matches one of the kept comments
+func (n *Normalizer) isKeptComment(token *Token) bool {
+	if n.config.KeepHints && isOptimizerHint(token.Value) {
+		return true
+	}
+	for _, prefix := range n.config.KeepCommentPrefixes {
+		if strings.HasPrefix(token.Value, prefix) {
+			return true
+		}
+	}
+	for _, pattern := range n.config.KeepCommentPatterns {
+		if pattern.MatchString(token.Value) {
+			return true
+		}
+	}
+	return false
+}
+
 func (n *Normalizer) shouldStripIdentifierQuotes(token *Token, lastValueToken *LastValueToken) bool {
 	if n.config.KeepIdentifierQuotation {
 		return false
@@ -429,7 +770,7 @@ func (n *Normalizer) writeToken(tokenType TokenType, tokenValue string, normaliz
 }
 
 func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *LastValueToken, groupablePlaceholder *groupablePlaceholder, normalizedSQLBuilder *strings.Builder) bool {
-	if token.Value == NumberPlaceholder || token.Value == StringPlaceholder {
+	if n.isGroupablePlaceholder(token.Type, token.Value) {
 		if lastValueToken == nil {
 			// if the last token is nil, we know it's the start of groupable placeholders
 			return false
@@ -444,7 +785,7 @@ func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *La
 		}
 	}
 
-	if lastValueToken != nil && (lastValueToken.Value == NumberPlaceholder || lastValueToken.Value == StringPlaceholder) && token.Value == "," && groupablePlaceholder.groupable {
+	if groupablePlaceholder.groupable && token.Value == "," && lastValueToken != nil && n.isGroupablePlaceholder(lastValueToken.Type, lastValueToken.Value) {
 		return true
 	}
 
@@ -454,7 +795,7 @@ func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *La
 		return false
 	}
 
-	if groupablePlaceholder.groupable && token.Value != NumberPlaceholder && token.Value != StringPlaceholder && lastValueToken != nil && lastValueToken.Value == "," {
+	if groupablePlaceholder.groupable && lastValueToken != nil && lastValueToken.Value == "," && !n.isGroupablePlaceholder(token.Type, token.Value) {
 		// This is a tricky edge case. If we are inside a groupbale block, and the current token is not a placeholder,
 		// we not only want to write the current token to the normalizedSQLBuilder, but also write the last comma that we skipped.
 		// For example, (?, ARRAY[?, ?, ?]) should be normalized as (?, ARRAY[?])
@@ -465,6 +806,15 @@ func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *La
 	return false
 }
 
+// isGroupablePlaceholder reports whether the token is an obfuscated value, or a positional or bind parameter
+// when GroupBindParameters is enabled
+func (n *Normalizer) isGroupablePlaceholder(tokenType TokenType, value string) bool {
+	if n.config.GroupBindParameters && (tokenType == POSITIONAL_PARAMETER || tokenType == BIND_PARAMETER) {
+		return true
+	}
+	return isObfuscatedPlaceholder(tokenType, value)
+}
+
 func (n *Normalizer) appendSpace(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder *strings.Builder, colonCtx *colonContext) {
 	// do not add a space between parentheses if RemoveSpaceBetweenParentheses is true
 	if n.config.RemoveSpaceBetweenParentheses && lastValueToken != nil && (lastValueToken.Type == FUNCTION || lastValueToken.Value == "(" || lastValueToken.Value == "[") {
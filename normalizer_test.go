@@ -2,6 +2,7 @@ package sqllexer
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1431,7 +1432,7 @@ func ExampleNormalizer() {
 	fmt.Println(normalizedSQL)
 	fmt.Println(statementMetadata)
 	// Output: SELECT * FROM users WHERE id in ( ? )
-	// &{34 [users] [/* this is a comment */] [SELECT] []}
+	// &{34 [users] [/* this is a comment */] [SELECT] [] map[] <nil> [] []   false [] [] <nil> [] [] false}
 }
 
 func TestNormalizerCTEWithoutCollectTables(t *testing.T) {
@@ -1651,6 +1652,234 @@ func TestNormalizerColonPrefixNegativeCases(t *testing.T) {
 	}
 }
 
+func TestNormalizerKeepComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		opts     []normalizerOption
+	}{
+		{
+			name:     "comments are dropped by default",
+			input:    "SELECT /*+ INDEX(users idx_users_id) */ * FROM users /* comment */",
+			expected: "SELECT * FROM users",
+		},
+		{
+			name:     "oracle and mysql hint",
+			input:    "SELECT /*+ INDEX(users idx_users_id) */ * FROM users /* comment */",
+			expected: "SELECT /*+ INDEX(users idx_users_id) */ * FROM users",
+			opts:     []normalizerOption{WithKeepHints(true)},
+		},
+		{
+			name:     "pg_hint_plan hint",
+			input:    "/*+ SeqScan(users) */ SELECT * FROM users WHERE id = ?",
+			expected: "/*+ SeqScan(users) */ SELECT * FROM users WHERE id = ?",
+			opts:     []normalizerOption{WithKeepHints(true)},
+		},
+		{
+			name:     "single line hint is rewritten as multiline comment",
+			input:    "SELECT --+ FULL(users)\n* FROM users",
+			expected: "SELECT /*+ FULL(users) */ * FROM users",
+			opts:     []normalizerOption{WithKeepHints(true)},
+		},
+		{
+			name:     "comment prefix allowlist",
+			input:    "/* keep: audit */ SELECT * FROM users /* drop */ -- keep me\n",
+			expected: "/* keep: audit */ SELECT * FROM users /* keep me */",
+			opts:     []normalizerOption{WithKeepCommentPrefixes("/* keep", "-- keep")},
+		},
+		{
+			name:     "comment pattern allowlist",
+			input:    "SELECT * FROM users /* ticket-123 */ /* other */",
+			expected: "SELECT * FROM users /* ticket-123 */",
+			opts:     []normalizerOption{WithKeepCommentPatterns(regexp.MustCompile(`ticket-\d+`))},
+		},
+		{
+			name:     "kept comment inside leading parentheses",
+			input:    "(SELECT /*+ FULL(users) */ * FROM users)",
+			expected: "( SELECT /*+ FULL(users) */ * FROM users )",
+			opts:     []normalizerOption{WithKeepHints(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := NewNormalizer(tt.opts...)
+			got, _, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+
+			// normalizing the output again should keep the comments
+			again, _, err := normalizer.Normalize(got)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, again)
+		})
+	}
+}
+
+func TestNormalizerCollapseRepeatedTuples(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "multi-row insert",
+			input:    "INSERT INTO t (a, b) VALUES (?, ?), (?, ?), (?, ?)",
+			expected: "INSERT INTO t ( a, b ) VALUES ( ? )",
+		},
+		{
+			name:     "multi-row insert followed by a clause",
+			input:    "INSERT INTO t (a) VALUES (?), (?) ON CONFLICT DO NOTHING",
+			expected: "INSERT INTO t ( a ) VALUES ( ? ) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:     "rows of positional parameters",
+			input:    "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4), ($5, $6)",
+			expected: "INSERT INTO t ( a, b ) VALUES ( $1, $2 )",
+		},
+		{
+			name:     "different rows are kept",
+			input:    "INSERT INTO t VALUES (?, now()), (?, now()), (?, DEFAULT), (?, DEFAULT)",
+			expected: "INSERT INTO t VALUES ( ?, now ( ) ), ( ?, DEFAULT )",
+		},
+		{
+			name:     "literals are not collapsed without obfuscation",
+			input:    "INSERT INTO t (a) VALUES (1), (2)",
+			expected: "INSERT INTO t ( a ) VALUES ( 1 ), ( 2 )",
+		},
+		{
+			name:     "row constructors",
+			input:    "SELECT ARRAY[ROW(?, ?), ROW(?, ?)], ARRAY[[?, ?], [?, ?]], ARRAY[ARRAY[?], ARRAY[?]]",
+			expected: "SELECT ARRAY [ ROW ( ? ) ], ARRAY [ [ ? ] ], ARRAY [ ARRAY [ ? ] ]",
+		},
+		{
+			name:     "tuples in an IN list",
+			input:    "SELECT * FROM t WHERE (a, b) IN ((?, ?), (?, ?))",
+			expected: "SELECT * FROM t WHERE ( a, b ) IN ( ( ? ) )",
+		},
+		{
+			name:     "values in a subquery",
+			input:    "SELECT * FROM (VALUES (?, ?), (?, ?)) AS v (id, name)",
+			expected: "SELECT * FROM ( VALUES ( ? ) ) ( id, name )",
+		},
+		{
+			name:     "nested repeated tuples",
+			input:    "INSERT INTO t VALUES (?, ROW(?, ?)), (?, ROW(?, ?))",
+			expected: "INSERT INTO t VALUES ( ?, ROW ( ? ) )",
+		},
+		{
+			name:     "parenthesized expressions of a select list are kept",
+			input:    "SELECT a, (?), (?) FROM t",
+			expected: "SELECT a, ( ? ), ( ? ) FROM t",
+		},
+		{
+			name:     "row used as a column name",
+			input:    "SELECT ARRAY[ROW(?), row, ROW(?)]",
+			expected: "SELECT ARRAY [ ROW ( ? ), row, ROW ( ? ) ]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := NewNormalizer(WithCollapseRepeatedTuples(true))
+			got, _, err := normalizer.Normalize(tt.input, WithDBMS(DBMSPostgres))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNormalizerGroupBindParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		dbms     DBMSType
+		group    bool
+	}{
+		{
+			name:     "positional parameters are kept by default",
+			input:    "SELECT * FROM users WHERE id IN ($1, $2, $3)",
+			expected: "SELECT * FROM users WHERE id IN ( $1, $2, $3 )",
+			dbms:     DBMSPostgres,
+		},
+		{
+			name:     "positional parameters",
+			input:    "SELECT * FROM users WHERE org_id = $1 AND id IN ($2, $3, $4, $5)",
+			expected: "SELECT * FROM users WHERE org_id = $1 AND id IN ( $2 )",
+			dbms:     DBMSPostgres,
+			group:    true,
+		},
+		{
+			name:     "oracle bind parameters",
+			input:    "SELECT * FROM users WHERE id IN (:1, :2, :3)",
+			expected: "SELECT * FROM users WHERE id IN ( :1 )",
+			dbms:     DBMSOracle,
+			group:    true,
+		},
+		{
+			name:     "sql server parameters",
+			input:    "SELECT * FROM users WHERE id IN (@p1, @p2)",
+			expected: "SELECT * FROM users WHERE id IN ( @p1 )",
+			dbms:     DBMSSQLServer,
+			group:    true,
+		},
+		{
+			name:     "parameters mixed with obfuscated values",
+			input:    "SELECT * FROM users WHERE id IN ($1, ?, $2) AND name = $3",
+			expected: "SELECT * FROM users WHERE id IN ( $1 ) AND name = $2",
+			dbms:     DBMSPostgres,
+			group:    true,
+		},
+		{
+			name:     "parameters following a run are renumbered",
+			input:    "SELECT * FROM users WHERE id IN ($1, $2, $3) AND b = $4 AND c = $4",
+			expected: "SELECT * FROM users WHERE id IN ( $1 ) AND b = $2 AND c = $2",
+			dbms:     DBMSPostgres,
+			group:    true,
+		},
+		{
+			name:     "oracle bind parameters following a run are renumbered",
+			input:    "SELECT * FROM users WHERE id IN (:1, :2) AND b = :3 AND c = :name",
+			expected: "SELECT * FROM users WHERE id IN ( :1 ) AND b = :2 AND c = :name",
+			dbms:     DBMSOracle,
+			group:    true,
+		},
+		{
+			name:     "function arguments",
+			input:    "SELECT * FROM users WHERE id = ANY(ARRAY[$1, $2, $3]) AND point = point($4, $5)",
+			expected: "SELECT * FROM users WHERE id = ANY ( ARRAY [ $1 ] ) AND point = point ( $2 )",
+			dbms:     DBMSPostgres,
+			group:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := NewNormalizer(WithGroupBindParameters(tt.group))
+			got, _, err := normalizer.Normalize(tt.input, WithDBMS(tt.dbms))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNormalizerGroupBindParametersAndCollapseRepeatedTuples(t *testing.T) {
+	normalizer := NewNormalizer(WithGroupBindParameters(true), WithCollapseRepeatedTuples(true))
+
+	expected := "INSERT INTO t ( a, b ) VALUES ( $1 ) ON CONFLICT ( a ) DO UPDATE SET b = $2"
+	for _, input := range []string{
+		"INSERT INTO t (a, b) VALUES ($1, $2) ON CONFLICT (a) DO UPDATE SET b = $3",
+		"INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4) ON CONFLICT (a) DO UPDATE SET b = $5",
+		"INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4), ($5, $6) ON CONFLICT (a) DO UPDATE SET b = $7",
+	} {
+		got, _, err := normalizer.Normalize(input, WithDBMS(DBMSPostgres))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, got)
+	}
+}
+
 func assertStatementMetadataEqual(t *testing.T, expected, actual *StatementMetadata) {
 	assert.Equal(t, expected.Size, actual.Size)
 	assert.Equal(t, expected.Tables, actual.Tables)
@@ -1,13 +1,52 @@
 package sqllexer
 
+import "slices"
+
 // ObfuscateAndNormalize takes an input SQL string and returns an normalized SQL string with metadata
 // This function is a convenience function that combines the Obfuscator and Normalizer in one pass
+// Numbered placeholders are numbered once grouped, e.g. IN (1, 2) AND b = 'x' is normalized as IN ( $1 ) AND b = $2
 func ObfuscateAndNormalize(input string, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+	// typed placeholders need the type of typed literals
+	lexerOpts = withTypedLiterals(lexerOpts, obfuscator.config.PlaceholderStyle == PlaceholderStyleTyped)
 	var ec extractContext
+	var cc credentialContext
+	var secrets []string
+	var hasCredentials bool
+	pc := obfuscator.newPlaceholderContext(input, lexerOpts...)
+	sc := obfuscator.newSensitiveColumnContext(input, lexerOpts...)
+	redact := func(token *Token) {
+		for _, rule := range obfuscator.redactSecrets(token) {
+			if !slices.Contains(secrets, rule) {
+				secrets = append(secrets, rule)
+			}
+		}
+	}
 	obfuscate := func(token *Token, lastValueToken *LastValueToken) {
-		obfuscator.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		if !sc.keep(token) {
+			obfuscator.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		}
+		if obfuscator.config.ScrubCredentials && cc.scrub(token) {
+			// credential clauses are redacted even when literals are kept
+			hasCredentials = true
+		}
+		redact(token)
 		ec.maybeReplaceExtractField(token)
 		ec.update(token)
 	}
-	return normalizer.normalize(input, obfuscate, lexerOpts...)
+	// the literals of kept comments such as hints are not literals of the query read ahead by the
+	// sensitive column context: they are always obfuscated
+	obfuscateCommentToken := func(token *Token, lastValueToken *LastValueToken) {
+		obfuscator.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		redact(token)
+	}
+	normalizedSQL, statementMetadata, err = normalizer.normalize(input, obfuscate, obfuscateCommentToken, pc, lexerOpts...)
+	if err == nil {
+		statementMetadata.HasCredentials = hasCredentials
+		for _, rule := range secrets {
+			if !slices.Contains(statementMetadata.Secrets, rule) {
+				statementMetadata.Secrets = append(statementMetadata.Secrets, rule)
+			}
+		}
+	}
+	return normalizedSQL, statementMetadata, err
 }
@@ -671,6 +671,119 @@ multiline comment */
 	}
 }
 
+func TestObfuscateAndNormalizeKeepHints(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		lexerOpts []lexerOption
+	}{
+		{
+			input:     "SELECT /*+ INDEX(users idx_users_id) MAX_EXECUTION_TIME(1000) */ * FROM users WHERE id = 42",
+			expected:  "SELECT /*+ INDEX(users idx_users_id) MAX_EXECUTION_TIME(?) */ * FROM users WHERE id = ?",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			input:    "/*+ Rows(a b #10) Set(random_page_cost 2.0) */ SELECT * FROM a JOIN b ON a.id = b.id",
+			expected: "/*+ Rows(a b #?) Set(random_page_cost ?) */ SELECT * FROM a JOIN b ON a.id = b.id",
+		},
+		{
+			input:     "SELECT /*+ OPT_PARAM('star_transformation_enabled' 'true') */ * FROM sales /* app='x' */",
+			expected:  "SELECT /*+ OPT_PARAM(? ?) */ * FROM sales",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+	}
+
+	obfuscator := NewObfuscator(WithReplaceDigits(true))
+	normalizer := NewNormalizer(
+		WithCollectComments(true),
+		WithKeepHints(true),
+	)
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got, _, err := ObfuscateAndNormalize(tt.input, obfuscator, normalizer, tt.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestObfuscateAndNormalizeKeepHintsWithSensitiveColumns(t *testing.T) {
+	// the hint literal equals the sensitive literal: it must not take its place in the sensitive column context
+	obfuscator := NewObfuscator(WithSensitiveColumns([]string{"email"}))
+	normalizer := NewNormalizer(WithKeepHints(true))
+	got, _, err := ObfuscateAndNormalize("SELECT /*+ SET_VAR(sort_buffer_size = 'x') */ * FROM users WHERE email = 'x' AND name = 'x'", obfuscator, normalizer, WithDBMS(DBMSMySQL))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT /*+ SET_VAR(sort_buffer_size = ?) */ * FROM users WHERE email = ? AND name = 'x'", got)
+}
+
+func TestObfuscateAndNormalizePlaceholderStyle(t *testing.T) {
+	tests := []struct {
+		input            string
+		expected         string
+		placeholderStyle PlaceholderStyle
+		dbms             DBMSType
+	}{
+		{
+			input:            "SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'",
+			expected:         "SELECT * FROM users WHERE id IN ( ?num ) AND name = ?str",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM events WHERE id = 0xff AND day IN (DATE '2024-01-01', DATE '2024-01-02') AND at > NOW() - INTERVAL '1 day'",
+			expected:         "SELECT * FROM events WHERE id = ?hex AND day IN ( DATE ?date, DATE ?date ) AND at > NOW ( ) - INTERVAL ?interval",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "INSERT INTO users (id, name) VALUES (1, 'bob')",
+			expected:         "INSERT INTO users ( id, name ) VALUES ( ?num )",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'",
+			expected:         "SELECT * FROM users WHERE id IN ( $1 ) AND name = $2",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+		{
+			input:            "SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'",
+			expected:         "SELECT * FROM users WHERE id IN ( :1 ) AND name = :2",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSOracle,
+		},
+		{
+			input:            "SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'",
+			expected:         "SELECT * FROM users WHERE id IN ( @P1 ) AND name = @P2",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSSQLServer,
+		},
+		{
+			// parameters of the query are not grouped with obfuscated literals
+			input:            "SELECT * FROM users WHERE id IN ($1, $2) AND org_id IN (1, 2)",
+			expected:         "SELECT * FROM users WHERE id IN ( $1, $2 ) AND org_id IN ( $3 )",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+		{
+			input:            "SELECT $func$SELECT 1$func$",
+			expected:         "SELECT $1",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+	}
+
+	normalizer := NewNormalizer()
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			obfuscator := NewObfuscator(WithPlaceholderStyle(tt.placeholderStyle))
+			got, _, err := ObfuscateAndNormalize(tt.input, obfuscator, normalizer, WithDBMS(tt.dbms))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
 // TestObfuscateAndNormalizeDoesNotPinLargeBackingArrays verifies that the ObfuscateAndNormalize
 // function returns strings that don't hold references to excessively large backing arrays.
 func TestObfuscateAndNormalizeDoesNotPinLargeBackingArrays(t *testing.T) {
@@ -687,3 +800,143 @@ func TestObfuscateAndNormalizeDoesNotPinLargeBackingArrays(t *testing.T) {
 		return BackingArrayTestResult{SQL: sql, Metadata: metadata}, err
 	})
 }
+
+func TestObfuscateAndNormalizeTypedLiterals(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithUppercaseKeywords(true), WithCollectLiteralTypes(true))
+	normalized, statementMetadata, err := ObfuscateAndNormalize(
+		"select * from events where at > now() - interval '1 day' and at < timestamp  with time zone '2024-01-01 00:00:00+00' and day = date '2024-01-01' and payload @> '{}'::jsonb",
+		obfuscator, normalizer, WithDBMS(DBMSPostgres),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events WHERE at > now ( ) - INTERVAL ? AND at < TIMESTAMP WITH TIME ZONE ? AND day = DATE ? AND payload @> ? :: jsonb", normalized)
+	assert.Equal(t, []string{"interval", "timestamp with time zone", "date", "jsonb"}, statementMetadata.LiteralTypes)
+}
+
+func TestObfuscateAndNormalizeCollapseRepeatedTuples(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollapseRepeatedTuples(true))
+
+	expected := "INSERT INTO users ( id, name, tags ) VALUES ( ? :: text [ ] )"
+	for _, input := range []string{
+		"INSERT INTO users (id, name, tags) VALUES (1, 'bob', '{a}'::text[])",
+		"INSERT INTO users (id, name, tags) VALUES (1, 'bob', '{a,b}'::text[]), (2, 'alice', '{}'::text[])",
+		"INSERT INTO users (id, name, tags) VALUES (1, 'bob', '{a}'::text[]),(2, 'alice', '{b}'::text[]),(3, 'eve', '{c,d}'::text[])",
+	} {
+		normalized, statementMetadata, err := ObfuscateAndNormalize(input, obfuscator, normalizer, WithDBMS(DBMSPostgres))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, normalized)
+		assert.Equal(t, []string{"users"}, statementMetadata.Tables)
+	}
+}
+
+func TestObfuscateAndNormalizeNumberedPlaceholdersAfterGrouping(t *testing.T) {
+	obfuscator := NewObfuscator(WithPlaceholderStyle(PlaceholderStyleNumbered))
+
+	for _, tt := range []struct {
+		normalizer *Normalizer
+		inputs     []string
+		expected   string
+	}{
+		{
+			normalizer: NewNormalizer(),
+			inputs: []string{
+				"SELECT * FROM users WHERE id IN (1) AND b = 'x'",
+				"SELECT * FROM users WHERE id IN (1, 2, 3) AND b = 'x'",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( $1 ) AND b = $2",
+		},
+		{
+			normalizer: NewNormalizer(WithCollapseRepeatedTuples(true)),
+			inputs: []string{
+				"INSERT INTO users (id, name) VALUES (1, 'bob') RETURNING id + 1",
+				"INSERT INTO users (id, name) VALUES (1, 'bob'), (2, 'alice') RETURNING id + 1",
+			},
+			expected: "INSERT INTO users ( id, name ) VALUES ( $1 ) RETURNING id + $2",
+		},
+		{
+			// the parameters of the input are renumbered along with the placeholders
+			normalizer: NewNormalizer(WithGroupBindParameters(true)),
+			inputs: []string{
+				"SELECT * FROM users WHERE id IN ($1) AND org_id IN (1) AND b = 'x'",
+				"SELECT * FROM users WHERE id IN ($1, $2) AND org_id IN (1, 2) AND b = 'x'",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( $1 ) AND org_id IN ( $2 ) AND b = $3",
+		},
+	} {
+		for _, input := range tt.inputs {
+			normalized, _, err := ObfuscateAndNormalize(input, obfuscator, tt.normalizer, WithDBMS(DBMSPostgres))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		}
+	}
+}
+
+func TestObfuscateAndNormalizeGroupBindParameters(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithGroupBindParameters(true), WithCollapseRepeatedTuples(true))
+
+	for _, tt := range []struct {
+		inputs   []string
+		expected string
+	}{
+		{
+			inputs: []string{
+				"SELECT * FROM users WHERE status = 'active' AND id IN ($1)",
+				"SELECT * FROM users WHERE status = 'active' AND id IN ($1, $2, $3)",
+				"SELECT * FROM users WHERE status = 'active' AND id IN ($1, $2, $3, $4, $5, $6, $7, $8)",
+			},
+			expected: "SELECT * FROM users WHERE status = ? AND id IN ( $1 )",
+		},
+		{
+			inputs: []string{
+				"SELECT * FROM users WHERE id IN ($1) AND b = $2",
+				"SELECT * FROM users WHERE id IN ($1, $2) AND b = $3",
+				"SELECT * FROM users WHERE id IN ($1, $2, $3) AND b = $4",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( $1 ) AND b = $2",
+		},
+		{
+			inputs: []string{
+				"INSERT INTO users (id, name) VALUES ($1, $2)",
+				"INSERT INTO users (id, name) VALUES ($1, $2), ($1, $2)",
+			},
+			expected: "INSERT INTO users ( id, name ) VALUES ( $1 )",
+		},
+	} {
+		for _, input := range tt.inputs {
+			normalized, _, err := ObfuscateAndNormalize(input, obfuscator, normalizer, WithDBMS(DBMSPostgres))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		}
+	}
+}
+
+func TestObfuscateAndNormalizeUnterminatedQuotedNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "SELECT x'ff FROM t WHERE a = 'secret'",
+			expected: "SELECT ? FROM t WHERE a = ?",
+		},
+		{
+			input:    "SELECT b'01 , password FROM t",
+			expected: "SELECT ?, password FROM t",
+		},
+		{
+			input:    "SELECT x'fg' FROM t WHERE a = 'secret'",
+			expected: "SELECT ? FROM t WHERE a = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			normalized, _, err := ObfuscateAndNormalize(tt.input, NewObfuscator(), NewNormalizer(), WithDBMS(DBMSMySQL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+			assert.NotContains(t, normalized, "secret")
+		})
+	}
+}
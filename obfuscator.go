@@ -1,6 +1,7 @@
 package sqllexer
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -12,10 +13,79 @@ type obfuscatorConfig struct {
 	ReplaceNull                bool `json:"replace_null"`
 	KeepJsonPath               bool `json:"keep_json_path"` // by default, we replace json path with placeholder
 	ReplaceBindParameter       bool `json:"replace_bind_parameter"`
+	// PlaceholderStyle is the style of the placeholders replacing literals, ? by default
+	PlaceholderStyle PlaceholderStyle `json:"placeholder_style"`
+	// MaskingKey is the HMAC key masking literals instead of replacing them with placeholders, see WithMaskingKey
+	MaskingKey []byte `json:"-"`
+	// SensitiveColumns are the lowercased column name patterns of WithSensitiveColumns
+	SensitiveColumns []string `json:"sensitive_columns"`
+	// SecretScanner redacts the secrets of the literals and comments left by the obfuscation
+	SecretScanner *SecretScanner `json:"-"`
+	// ScrubCredentials redacts the secrets of credential clauses, see WithScrubCredentials
+	ScrubCredentials bool `json:"scrub_credentials"`
 }
 
+// PlaceholderStyle is the style of the placeholders replacing obfuscated literals
+type PlaceholderStyle int
+
+const (
+	// PlaceholderStyleQuestionMark replaces every literal with ?
+	PlaceholderStyleQuestionMark PlaceholderStyle = iota
+	// PlaceholderStyleNumbered replaces literals with placeholders numbered in the style of the DBMS,
+	// so queries match the text of pg_stat_statements ($1), Oracle V$SQL (:1) or SQL Server Query Store (@P1).
+	// Other DBMS use ?.
+	PlaceholderStyleNumbered
+	// PlaceholderStyleTyped replaces literals with a placeholder of their type, e.g. ?str, ?num or ?date,
+	// to tell apart queries passing strings to number columns
+	PlaceholderStyleTyped
+)
+
 type obfuscatorOption func(*obfuscatorConfig)
 
+func WithPlaceholderStyle(placeholderStyle PlaceholderStyle) obfuscatorOption {
+	return func(c *obfuscatorConfig) {
+		c.PlaceholderStyle = placeholderStyle
+	}
+}
+
+// WithMaskingKey masks number and string literals with a keyed HMAC of their value instead of replacing
+// them with placeholders, so the same value masks the same way across queries without being revealed.
+// Strings become 'h:3fa9c1' and numbers become numbers of the same length. An empty key disables masking.
+func WithMaskingKey(maskingKey []byte) obfuscatorOption {
+	return func(c *obfuscatorConfig) {
+		c.MaskingKey = maskingKey
+	}
+}
+
+// WithSensitiveColumns only obfuscates the literals compared to, inserted into or assigned to a column
+// matching one of the patterns, and keeps the other literals as they are.
+// Patterns are matched against the unqualified column name, ignoring case, and may use the wildcards
+// of path.Match, e.g. password, *ssn* or email.
+func WithSensitiveColumns(patterns []string) obfuscatorOption {
+	return func(c *obfuscatorConfig) {
+		c.SensitiveColumns = make([]string, len(patterns))
+		for i, pattern := range patterns {
+			c.SensitiveColumns[i] = strings.ToLower(pattern)
+		}
+	}
+}
+
+// WithSecretScanner redacts the secrets found by the scanner in the literals left by the obfuscation,
+// e.g. with KeepJsonPath or WithSensitiveColumns, and in comments
+func WithSecretScanner(secretScanner *SecretScanner) obfuscatorOption {
+	return func(c *obfuscatorConfig) {
+		c.SecretScanner = secretScanner
+	}
+}
+
+// WithScrubCredentials redacts the secrets of credential clauses, such as PASSWORD 'x' or IDENTIFIED BY 'x',
+// even when literals are kept, e.g. with WithSensitiveColumns or WithMaskingKey
+func WithScrubCredentials(scrubCredentials bool) obfuscatorOption {
+	return func(c *obfuscatorConfig) {
+		c.ScrubCredentials = scrubCredentials
+	}
+}
+
 func WithReplaceDigits(replaceDigits bool) obfuscatorOption {
 	return func(c *obfuscatorConfig) {
 		c.ReplaceDigits = replaceDigits
@@ -77,11 +147,27 @@ func NewObfuscator(opts ...obfuscatorOption) *Obfuscator {
 const (
 	StringPlaceholder = "?"
 	NumberPlaceholder = "?"
+
+	// typed placeholders of PlaceholderStyleTyped
+	TypedStringPlaceholder  = "?str"
+	TypedNumberPlaceholder  = "?num"
+	TypedBooleanPlaceholder = "?bool"
+	TypedNullPlaceholder    = "?null"
+	// hex (0xff, x'ff') and binary (0b0101, b'0101') literals
+	TypedHexPlaceholder    = "?hex"
+	TypedBinaryPlaceholder = "?bin"
+	// typed strings such as DATE '2024-01-01', TIMESTAMP '2024-01-01 00:00:00' and INTERVAL '1 day'
+	TypedDatePlaceholder      = "?date"
+	TypedTimePlaceholder      = "?time"
+	TypedTimestampPlaceholder = "?timestamp"
+	TypedIntervalPlaceholder  = "?interval"
 )
 
 // Obfuscate takes an input SQL string and returns an obfuscated SQL string.
 // The obfuscator replaces all literal values with a single placeholder
 func (o *Obfuscator) Obfuscate(input string, lexerOpts ...lexerOption) string {
+	// typed placeholders need the type of typed literals
+	lexerOpts = withTypedLiterals(lexerOpts, o.config.PlaceholderStyle == PlaceholderStyleTyped)
 	var obfuscatedSQL strings.Builder
 	obfuscatedSQL.Grow(len(input))
 
@@ -92,14 +178,24 @@ func (o *Obfuscator) Obfuscate(input string, lexerOpts ...lexerOption) string {
 
 	var lastValueToken *LastValueToken
 	var ec extractContext
+	pc := o.newPlaceholderContext(input, lexerOpts...)
+	sc := o.newSensitiveColumnContext(input, lexerOpts...)
+	var cc credentialContext
 
 	for {
 		token := lexer.Scan()
 		if token.Type == EOF {
 			break
 		}
-		o.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		if !sc.keep(token) {
+			o.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		}
+		if o.config.ScrubCredentials {
+			cc.scrub(token)
+		}
+		o.redactSecrets(token)
 		ec.maybeReplaceExtractField(token)
+		pc.number(token)
 
 		obfuscatedSQL.WriteString(token.Value)
 		if isValueToken(token) {
@@ -111,13 +207,20 @@ func (o *Obfuscator) Obfuscate(input string, lexerOpts ...lexerOption) string {
 	return strings.Clone(strings.TrimSpace(obfuscatedSQL.String()))
 }
 
+// ObfuscateTokenValue replaces the value of a literal token with a placeholder.
+// Numbered placeholders are only written by Obfuscate and ObfuscateAndNormalize, which number the
+// ? placeholders of the query in order.
 func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValueToken, lexerOpts ...lexerOption) {
 	switch token.Type {
 	case NUMBER:
 		if o.config.KeepJsonPath && lastValueToken != nil && lastValueToken.Type == JSON_OP {
 			break
 		}
-		token.Value = NumberPlaceholder
+		if len(o.config.MaskingKey) > 0 {
+			token.Value = maskNumber(o.config.MaskingKey, token.Value)
+			break
+		}
+		token.Value = o.literalPlaceholder(token, lastValueToken, NumberPlaceholder)
 	case DOLLAR_QUOTED_FUNCTION:
 		if o.config.DollarQuotedFunc {
 			// obfuscate the content of dollar quoted function
@@ -130,12 +233,16 @@ func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValue
 			token.Value = obfuscatedDollarQuotedFunc.String()
 			break
 		}
-		token.Value = StringPlaceholder
+		token.Value = o.placeholder(StringPlaceholder, TypedStringPlaceholder)
 	case STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING:
 		if o.config.KeepJsonPath && lastValueToken != nil && lastValueToken.Type == JSON_OP {
 			break
 		}
-		token.Value = StringPlaceholder
+		if len(o.config.MaskingKey) > 0 {
+			token.Value = maskString(o.config.MaskingKey, token)
+			break
+		}
+		token.Value = o.literalPlaceholder(token, lastValueToken, StringPlaceholder)
 	case POSITIONAL_PARAMETER:
 		if o.config.ReplacePositionalParameter {
 			token.Value = StringPlaceholder
@@ -146,11 +253,11 @@ func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValue
 		}
 	case BOOLEAN:
 		if o.config.ReplaceBoolean {
-			token.Value = StringPlaceholder
+			token.Value = o.placeholder(StringPlaceholder, TypedBooleanPlaceholder)
 		}
 	case NULL:
 		if o.config.ReplaceNull {
-			token.Value = StringPlaceholder
+			token.Value = o.placeholder(StringPlaceholder, TypedNullPlaceholder)
 		}
 	case IDENT, QUOTED_IDENT:
 		if o.config.ReplaceDigits && token.hasDigits {
@@ -158,3 +265,180 @@ func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValue
 		}
 	}
 }
+
+// placeholder returns the typed placeholder with PlaceholderStyleTyped, placeholder otherwise
+func (o *Obfuscator) placeholder(placeholder string, typedPlaceholder string) string {
+	if o.config.PlaceholderStyle == PlaceholderStyleTyped {
+		return typedPlaceholder
+	}
+	return placeholder
+}
+
+// redactSecrets redacts the secrets of the token with the secret scanner, if any,
+// and returns the names of the rules that fired
+func (o *Obfuscator) redactSecrets(token *Token) []string {
+	if o.config.SecretScanner == nil {
+		return nil
+	}
+	return o.config.SecretScanner.redactToken(token)
+}
+
+// literalPlaceholder returns the placeholder of a number or string literal
+func (o *Obfuscator) literalPlaceholder(token *Token, lastValueToken *LastValueToken, placeholder string) string {
+	if o.config.PlaceholderStyle != PlaceholderStyleTyped {
+		return placeholder
+	}
+	// the type of a typed string literal, e.g. DATE '2024-01-01' or TIMESTAMP WITH TIME ZONE '...'
+	switch literalType := token.LiteralType(); {
+	case literalType == "date":
+		return TypedDatePlaceholder
+	case literalType == "time", strings.HasPrefix(literalType, "time "):
+		return TypedTimePlaceholder
+	case strings.HasPrefix(literalType, "timestamp"), literalType == "datetime":
+		return TypedTimestampPlaceholder
+	case literalType == "interval":
+		return TypedIntervalPlaceholder
+	}
+	if lastValueToken != nil {
+		// the type of a string or number is given by the preceding keyword or prefix, e.g. MySQL INTERVAL 1 DAY or x'ff'
+		switch {
+		case token.Type == NUMBER && strings.EqualFold(lastValueToken.Value, "INTERVAL"):
+			return TypedIntervalPlaceholder
+		case token.Type == STRING && lastValueToken.Type == IDENT && strings.EqualFold(lastValueToken.Value, "X"):
+			return TypedHexPlaceholder
+		case token.Type == STRING && lastValueToken.Type == IDENT && strings.EqualFold(lastValueToken.Value, "B"):
+			return TypedBinaryPlaceholder
+		}
+	}
+	if token.Type == NUMBER {
+		switch radix, _ := numberRadix(token.Value); radix {
+		case 'x':
+			return TypedHexPlaceholder
+		case 'b':
+			return TypedBinaryPlaceholder
+		}
+		return TypedNumberPlaceholder
+	}
+	return TypedStringPlaceholder
+}
+
+// placeholderContext numbers the placeholders of a query with PlaceholderStyleNumbered,
+// and renumbers the parameters of the input grouped by the normalizer with WithGroupBindParameters
+type placeholderContext struct {
+	enabled bool
+	dbms    DBMSType
+	// position is the number of the last placeholder
+	position int
+	// numbers maps the numbers of the parameters of the input to their new number
+	numbers map[int]int
+}
+
+// newPlaceholderContext returns the placeholder context of the input. Like pg_stat_statements,
+// the numbering starts after the numbered parameters already in the input.
+func (o *Obfuscator) newPlaceholderContext(input string, lexerOpts ...lexerOption) placeholderContext {
+	if o.config.PlaceholderStyle != PlaceholderStyleNumbered {
+		return placeholderContext{}
+	}
+	config := &LexerConfig{}
+	for _, opt := range lexerOpts {
+		opt(config)
+	}
+	c := placeholderContext{enabled: true, dbms: config.DBMS}
+	if strings.ContainsAny(input, "$:@") {
+		for token := range New(input, lexerOpts...).All() {
+			c.position = max(c.position, existingPlaceholderNumber(&token))
+		}
+	}
+	return c
+}
+
+// number replaces the ? placeholder written by the obfuscator with the next numbered placeholder
+func (c *placeholderContext) number(token *Token) {
+	if !c.enabled || token.Value != StringPlaceholder || token.Type == OPERATOR {
+		return
+	}
+	c.position++
+	if c.dbms == DBMSSQLServer {
+		// Query Store reports parameters as @P1
+		token.Value = "@P" + strconv.Itoa(c.position)
+		return
+	}
+	token.Value = numberedPlaceholder(c.dbms, c.position)
+}
+
+// renumber numbers the parameters of the input in order of appearance, keeping their prefix,
+// so that the parameters following a grouped run do not depend on its length.
+// A parameter used more than once keeps the same number.
+func (c *placeholderContext) renumber(token *Token) {
+	number := existingPlaceholderNumber(token)
+	if number == 0 {
+		return
+	}
+	renumbered, ok := c.numbers[number]
+	if !ok {
+		if c.numbers == nil {
+			c.numbers = map[int]int{}
+		}
+		c.position++
+		renumbered = c.position
+		c.numbers[number] = renumbered
+	}
+	token.Value = strings.TrimRight(token.Value, "0123456789") + strconv.Itoa(renumbered)
+}
+
+// reset gives the numbers following position again, e.g. after the tuple they were given in is dropped
+func (c *placeholderContext) reset(position int) {
+	c.position = position
+	for number, renumbered := range c.numbers {
+		if renumbered > position {
+			delete(c.numbers, number)
+		}
+	}
+}
+
+// isObfuscatedPlaceholder reports whether the value of the token is a placeholder of any style
+func isObfuscatedPlaceholder(tokenType TokenType, value string) bool {
+	// placeholders start with one of ?$:@, all below the letters: keywords and identifiers are rejected
+	// by this inlinable check as it is called for every token by the normalizer, as is the common ?
+	return value == StringPlaceholder || value != "" && value[0] <= '@' && isPlaceholderValue(tokenType, value)
+}
+
+// isPlaceholderValue reports whether the value is a placeholder of any style
+func isPlaceholderValue(tokenType TokenType, value string) bool {
+	if value[0] != '?' && value[0] != '$' && value[0] != ':' && value[0] != '@' {
+		return false
+	}
+	switch value {
+	case StringPlaceholder, TypedStringPlaceholder, TypedNumberPlaceholder, TypedBooleanPlaceholder, TypedNullPlaceholder,
+		TypedHexPlaceholder, TypedBinaryPlaceholder, TypedDatePlaceholder, TypedTimePlaceholder, TypedTimestampPlaceholder, TypedIntervalPlaceholder:
+		return true
+	}
+	switch tokenType {
+	case OPERATOR, POSITIONAL_PARAMETER, BIND_PARAMETER:
+		// placeholders of the input
+		return false
+	}
+	return isNumberedPlaceholder(value)
+}
+
+// isNumberedPlaceholder reports whether the value is a numbered placeholder such as $1, :1 or @P1
+func isNumberedPlaceholder(value string) bool {
+	var digits string
+	switch {
+	case strings.HasPrefix(value, "$"), strings.HasPrefix(value, ":"):
+		digits = value[1:]
+	case strings.HasPrefix(value, "@P"), strings.HasPrefix(value, "@p"):
+		digits = value[2:]
+	default:
+		return false
+	}
+	if digits == "" {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(rune(digits[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -623,6 +623,108 @@ func TestObfuscator(t *testing.T) {
 	}
 }
 
+func TestObfuscatorPlaceholderStyle(t *testing.T) {
+	tests := []struct {
+		input            string
+		expected         string
+		placeholderStyle PlaceholderStyle
+		dbms             DBMSType
+	}{
+		{
+			input:            "SELECT * FROM users WHERE name = 'bob' AND id IN (1, 2) AND active = TRUE AND deleted_at IS NULL",
+			expected:         "SELECT * FROM users WHERE name = ? AND id IN (?, ?) AND active = ? AND deleted_at IS ?",
+			placeholderStyle: PlaceholderStyleQuestionMark,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = 'bob' AND id IN (1, 2) AND active = TRUE AND deleted_at IS NULL",
+			expected:         "SELECT * FROM users WHERE name = ?str AND id IN (?num, ?num) AND active = ?bool AND deleted_at IS ?null",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = $$bob$$ AND id = -1.5e3",
+			expected:         "SELECT * FROM users WHERE name = ?str AND id = ?num",
+			placeholderStyle: PlaceholderStyleTyped,
+			dbms:             DBMSPostgres,
+		},
+		{
+			input:            "SELECT * FROM files WHERE hash = 0x1F AND checksum = x'ff' AND flags = B'0101'",
+			expected:         "SELECT * FROM files WHERE hash = ?hex AND checksum = x?hex AND flags = B?bin",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM events WHERE day = DATE '2024-01-01' AND at < TIMESTAMP '2024-01-01 10:00:00' AND start = TIME '10:00'",
+			expected:         "SELECT * FROM events WHERE day = DATE ?date AND at < TIMESTAMP ?timestamp AND start = TIME ?time",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM events WHERE at > NOW() - INTERVAL '1 day' AND at < NOW() + INTERVAL 2 HOUR",
+			expected:         "SELECT * FROM events WHERE at > NOW() - INTERVAL ?interval AND at < NOW() + INTERVAL ?interval HOUR",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM events WHERE at < TIMESTAMP WITH TIME ZONE '2024-01-01 10:00:00+00' AND payload = '{}'::jsonb AND at AT TIME ZONE 'UTC' > x",
+			expected:         "SELECT * FROM events WHERE at < TIMESTAMP WITH TIME ZONE ?timestamp AND payload = ?str::jsonb AND at AT TIME ZONE ?str > x",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			// a string passed to a number column
+			input:            "SELECT * FROM users WHERE id = '42'",
+			expected:         "SELECT * FROM users WHERE id = ?str",
+			placeholderStyle: PlaceholderStyleTyped,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = 'bob' AND id IN (1, 2)",
+			expected:         "SELECT * FROM users WHERE name = $1 AND id IN ($2, $3)",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+		{
+			// numbering starts after the parameters of the query, like pg_stat_statements
+			input:            "SELECT * FROM users WHERE name = $2 AND id = 1 AND org_id = $1",
+			expected:         "SELECT * FROM users WHERE name = $2 AND id = $3 AND org_id = $1",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = 'bob' AND id = :1",
+			expected:         "SELECT * FROM users WHERE name = :2 AND id = :1",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSOracle,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = N'bob' AND id = 1",
+			expected:         "SELECT * FROM users WHERE name = N@P1 AND id = @P2",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSSQLServer,
+		},
+		{
+			input:            "SELECT * FROM users WHERE name = 'bob' AND id = 1",
+			expected:         "SELECT * FROM users WHERE name = ? AND id = ?",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSMySQL,
+		},
+		{
+			input:            "SELECT EXTRACT(YEAR FROM created_at) FROM users WHERE id = 1",
+			expected:         "SELECT EXTRACT($1 FROM created_at) FROM users WHERE id = $2",
+			placeholderStyle: PlaceholderStyleNumbered,
+			dbms:             DBMSPostgres,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			obfuscator := NewObfuscator(
+				WithReplaceDigits(true),
+				WithReplaceBoolean(true),
+				WithReplaceNull(true),
+				WithPlaceholderStyle(tt.placeholderStyle),
+			)
+			got := obfuscator.Obfuscate(tt.input, WithDBMS(tt.dbms))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
 func ExampleObfuscator() {
 	obfuscator := NewObfuscator()
 	obfuscated := obfuscator.Obfuscate("SELECT * FROM users WHERE id = 1")
@@ -0,0 +1,346 @@
+package sqllexer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParameterType is the type of a literal extracted by Parameterize
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeNumber  ParameterType = "number"
+	ParameterTypeBoolean ParameterType = "boolean"
+	ParameterTypeNull    ParameterType = "null"
+)
+
+// Parameter is a literal extracted by Parameterize
+type Parameter struct {
+	// Position is the 1-based position of the parameter, the number of its placeholder
+	Position int `json:"position"`
+	// Placeholder is the placeholder replacing the literal in the query, e.g. $1
+	Placeholder string        `json:"placeholder"`
+	Type        ParameterType `json:"type"`
+	// Value is the value of the literal: strings are unquoted and unescaped, e.g. it's for 'it''s'
+	Value string `json:"value"`
+	// Offset is the byte offset of the literal in the input
+	Offset int `json:"offset"`
+}
+
+// numberedPlaceholder returns the placeholder of the parameter at position in the style of the DBMS:
+// $1 for PostgreSQL, :1 for Oracle, @p1 for SQL Server and ? otherwise
+func numberedPlaceholder(dbms DBMSType, position int) string {
+	switch dbms {
+	case DBMSPostgres:
+		return "$" + strconv.Itoa(position)
+	case DBMSOracle:
+		return ":" + strconv.Itoa(position)
+	case DBMSSQLServer:
+		return "@p" + strconv.Itoa(position)
+	}
+	return "?"
+}
+
+// parameterizeToken is a token of the parameterized input with its byte offset
+type parameterizeToken struct {
+	Token
+	offset int
+}
+
+// Parameterize replaces the literals of the input with numbered placeholders in the style of the DBMS
+// and returns them as typed parameters, e.g. for replaying a query as a prepared statement.
+// Each value of an IN list is a parameter. Placeholders already in the input are kept and the numbering
+// of the new ones starts after them. Literals that cannot be bound are kept, such as binary strings (x'ff'),
+// typed literals (DATE '2024-01-01', INTERVAL '1 day') and IS NULL, and so are the literals that are part of
+// the shape of the query: type modifiers (VARCHAR(50)), ORDER BY and GROUP BY ordinals and DDL defaults.
+func Parameterize(input string, lexerOpts ...lexerOption) (string, []Parameter) {
+	lexer := New(input, lexerOpts...)
+	dbms := lexer.config.DBMS
+
+	var tokens []parameterizeToken
+	offset := 0
+	position := 0
+	for token := range lexer.All() {
+		tokens = append(tokens, parameterizeToken{Token: token, offset: offset})
+		offset += len(token.Value)
+		position = max(position, existingPlaceholderNumber(&token))
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(input))
+	parameters := []Parameter{}
+	// previous holds the indexes of the last three value tokens
+	previous := [3]int{-1, -1, -1}
+	var shape queryShape
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		parameter, end, ok := parameterOf(tokens, i, previous, dbms)
+		if ok && shape.isPart(tokens, i, previous) {
+			ok = false
+			end = i
+		}
+		if ok {
+			position++
+			parameter.Position = position
+			parameter.Placeholder = numberedPlaceholder(dbms, position)
+			if parameter.Type == ParameterTypeNumber && isLeadingSign(rune(parameter.Value[0])) && isOperand(tokens, previous[0]) {
+				// a - -1 is a subtraction lexed as a signed number: keep the sign in the query
+				builder.WriteByte(parameter.Value[0])
+				parameter.Value = parameter.Value[1:]
+				parameter.Offset++
+			}
+			if isStringPrefix(tokens, i) {
+				// N'x' and E'x': the prefix is part of the literal
+				parameter.Offset = tokens[i-1].offset
+			}
+			builder.WriteString(parameter.Placeholder)
+			parameters = append(parameters, parameter)
+			i = end
+		} else if !isStringPrefix(tokens, i+1) || !isBindablePrefix(token.Value) {
+			builder.WriteString(token.Value)
+		}
+		if isValueToken(&tokens[i].Token) {
+			shape.update(tokens, i, previous)
+			previous = [3]int{i, previous[0], previous[1]}
+		}
+	}
+	return strings.Clone(builder.String()), parameters
+}
+
+// parameterOf returns the parameter of the literal starting at index i and the index of its last token
+func parameterOf(tokens []parameterizeToken, i int, previous [3]int, dbms DBMSType) (Parameter, int, bool) {
+	token := &tokens[i]
+	parameter := Parameter{Offset: token.offset}
+	switch token.Type {
+	case NUMBER:
+		if previous[0] >= 0 && tokens[previous[0]].Value == ":" && previous[0] == i-1 {
+			// :1 bind variable
+			return parameter, i, false
+		}
+		if radix, _ := numberRadix(token.Value); token.IsMalformed() || radix != 0 && strings.HasSuffix(token.Value, "'") {
+			// malformed numbers cannot be bound, and MySQL x'ff' and b'0101' are binary strings
+			return parameter, i, false
+		}
+		parameter.Type = ParameterTypeNumber
+		parameter.Value = token.Value
+		return parameter, i, true
+	case BOOLEAN, NULL:
+		if previous[0] >= 0 && (strings.EqualFold(tokens[previous[0]].Value, "IS") ||
+			strings.EqualFold(tokens[previous[0]].Value, "NOT") && previous[1] >= 0 && strings.EqualFold(tokens[previous[1]].Value, "IS")) {
+			// IS NULL, IS NOT TRUE
+			return parameter, i, false
+		}
+		parameter.Type = ParameterTypeBoolean
+		if token.Type == NULL {
+			parameter.Type = ParameterTypeNull
+		}
+		parameter.Value = token.Value
+		return parameter, i, true
+	case DOLLAR_QUOTED_STRING:
+		tag := strings.IndexByte(token.Value[1:], '$') + 2
+		parameter.Type = ParameterTypeString
+		parameter.Value = token.Value[tag : len(token.Value)-tag]
+		return parameter, i, true
+	case STRING, INCOMPLETE_STRING:
+		if isTypedLiteral(tokens, previous) {
+			return parameter, i, false
+		}
+		backslash := dbms == DBMSMySQL
+		if isStringPrefix(tokens, i) {
+			prefix := strings.ToUpper(tokens[i-1].Value)
+			if !isBindablePrefix(prefix) {
+				return parameter, i, false
+			}
+			backslash = backslash || prefix == "E"
+		}
+		// 'it''s' is lexed as the adjacent strings 'it' and 's'
+		end := i
+		for end+1 < len(tokens) && tokens[end].Type == STRING && (tokens[end+1].Type == STRING || tokens[end+1].Type == INCOMPLETE_STRING) {
+			end++
+		}
+		var value strings.Builder
+		for j := i; j <= end; j++ {
+			if j > i {
+				value.WriteByte('\'')
+			}
+			value.WriteString(unquoteString(&tokens[j].Token, backslash))
+		}
+		parameter.Type = ParameterTypeString
+		parameter.Value = value.String()
+		return parameter, end, true
+	}
+	return parameter, i, false
+}
+
+// queryShape tracks the clauses whose literals are part of the shape of the query rather than values
+type queryShape struct {
+	// depth is the parenthesis depth
+	depth int
+	// typeModifierDepth is the depth inside the modifiers of a type such as NUMERIC(10, 2), 0 outside
+	typeModifierDepth int
+	// inOrdinals is true in an ORDER BY or GROUP BY list opened at ordinalsDepth
+	inOrdinals    bool
+	ordinalsDepth int
+}
+
+// isPart reports whether the literal at index i is part of the shape of the query:
+// a type modifier, an ORDER BY or GROUP BY ordinal or a DEFAULT value
+func (s *queryShape) isPart(tokens []parameterizeToken, i int, previous [3]int) bool {
+	if s.typeModifierDepth > 0 && tokens[i].Type == NUMBER {
+		return true
+	}
+	if previous[0] < 0 {
+		return false
+	}
+	last := tokens[previous[0]].Value
+	if strings.EqualFold(last, "DEFAULT") ||
+		last == "(" && previous[1] >= 0 && strings.EqualFold(tokens[previous[1]].Value, "DEFAULT") {
+		// DEFAULT 'x', DEFAULT ('x')
+		return true
+	}
+	return tokens[i].Type == NUMBER && s.inOrdinals && s.ordinalsDepth == s.depth &&
+		(strings.EqualFold(last, "BY") || last == ",")
+}
+
+// update tracks the value token at index i
+func (s *queryShape) update(tokens []parameterizeToken, i int, previous [3]int) {
+	token := &tokens[i]
+	switch {
+	case token.Type == PUNCTUATION && token.Value == "(":
+		s.depth++
+		if s.typeModifierDepth > 0 {
+			s.typeModifierDepth++
+		} else if previous[0] >= 0 && isModifiedType(tokens[previous[0]].Value) {
+			s.typeModifierDepth = 1
+		}
+	case token.Type == PUNCTUATION && token.Value == ")":
+		if s.typeModifierDepth > 0 {
+			s.typeModifierDepth--
+		}
+		if s.inOrdinals && s.ordinalsDepth == s.depth {
+			// the list ends with its enclosing parentheses, e.g. OVER (ORDER BY 1)
+			s.inOrdinals = false
+		}
+		s.depth--
+	case token.Type == PUNCTUATION && token.Value == ";":
+		*s = queryShape{}
+	case strings.EqualFold(token.Value, "BY"):
+		if previous[0] >= 0 && (strings.EqualFold(tokens[previous[0]].Value, "ORDER") || strings.EqualFold(tokens[previous[0]].Value, "GROUP")) {
+			s.inOrdinals = true
+			s.ordinalsDepth = s.depth
+		}
+	case token.Type == COMMAND || token.Type == KEYWORD:
+		if s.ordinalsDepth == s.depth && !strings.EqualFold(token.Value, "ASC") && !strings.EqualFold(token.Value, "DESC") {
+			// the list ends with the next clause, e.g. LIMIT 10, 20
+			s.inOrdinals = false
+		}
+	}
+}
+
+// isModifiedType reports whether the name is a type taking numeric modifiers, e.g. VARCHAR(50) or NUMERIC(10, 2)
+func isModifiedType(name string) bool {
+	switch strings.ToUpper(name) {
+	case "CHAR", "CHARACTER", "VARCHAR", "VARCHAR2", "NCHAR", "NVARCHAR", "NVARCHAR2", "VARYING",
+		"BINARY", "VARBINARY", "BIT", "RAW", "DECIMAL", "DEC", "NUMERIC", "NUMBER", "FLOAT", "DOUBLE", "REAL",
+		"INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT",
+		"TIME", "TIMESTAMP", "DATETIME", "DATETIME2", "DATETIMEOFFSET":
+		return true
+	}
+	return false
+}
+
+// existingPlaceholderNumber returns the number of a numbered placeholder such as $2, :2, @p2 or @P2, or 0
+func existingPlaceholderNumber(token *Token) int {
+	var digits string
+	switch {
+	case token.Type == POSITIONAL_PARAMETER:
+		digits = token.Value[1:]
+	case token.Type == BIND_PARAMETER && strings.HasPrefix(token.Value, ":"):
+		digits = token.Value[1:]
+	case token.Type == BIND_PARAMETER && (strings.HasPrefix(token.Value, "@p") || strings.HasPrefix(token.Value, "@P")):
+		digits = token.Value[2:]
+	}
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// isOperand reports whether the value token at index i ends an operand, so that a following sign is an operator
+func isOperand(tokens []parameterizeToken, i int) bool {
+	if i < 0 {
+		return false
+	}
+	switch tokens[i].Type {
+	case IDENT, QUOTED_IDENT, NUMBER, STRING, DOLLAR_QUOTED_STRING, POSITIONAL_PARAMETER, BIND_PARAMETER, BOOLEAN, NULL:
+		return true
+	case PUNCTUATION:
+		return tokens[i].Value == ")" || tokens[i].Value == "]"
+	case OPERATOR:
+		return tokens[i].Value == "?"
+	}
+	return false
+}
+
+// isStringPrefix reports whether the string at index i has a prefix such as N'x', E'x' or x'ff'
+func isStringPrefix(tokens []parameterizeToken, i int) bool {
+	return i > 0 && i < len(tokens) && tokens[i].Type == STRING && tokens[i-1].Type == IDENT && len(tokens[i-1].Value) == 1
+}
+
+// isBindablePrefix reports whether a string with the prefix can be replaced by a string parameter
+func isBindablePrefix(prefix string) bool {
+	return strings.EqualFold(prefix, "N") || strings.EqualFold(prefix, "E")
+}
+
+// isTypedLiteral reports whether the string following the previous value tokens is a typed literal,
+// e.g. DATE '2024-01-01', INTERVAL '1 day' or TIMESTAMP WITH TIME ZONE '2024-01-01 00:00:00+00'
+func isTypedLiteral(tokens []parameterizeToken, previous [3]int) bool {
+	if previous[0] < 0 {
+		return false
+	}
+	switch strings.ToUpper(tokens[previous[0]].Value) {
+	case "DATE", "TIME", "TIMESTAMP", "TIMESTAMPTZ", "INTERVAL":
+		return true
+	case "ZONE":
+		// WITH TIME ZONE is a type, AT TIME ZONE 'UTC' is an expression
+		return previous[1] >= 0 && strings.EqualFold(tokens[previous[1]].Value, "TIME") &&
+			previous[2] >= 0 && (strings.EqualFold(tokens[previous[2]].Value, "WITH") || strings.EqualFold(tokens[previous[2]].Value, "WITHOUT"))
+	}
+	return false
+}
+
+// unquoteString returns the content of a quoted string, unescaping doubled quotes,
+// and backslash escapes if backslash is true
+func unquoteString(token *Token, backslash bool) string {
+	value := token.Value[1:]
+	if token.Type == STRING {
+		value = value[:len(value)-1]
+	}
+	if !backslash || !strings.Contains(value, "\\") {
+		return value
+	}
+	var unescaped strings.Builder
+	unescaped.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			unescaped.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 't':
+			unescaped.WriteByte('\t')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case '0':
+			unescaped.WriteByte(0)
+		default:
+			unescaped.WriteByte(value[i])
+		}
+	}
+	return unescaped.String()
+}
@@ -0,0 +1,140 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameterize(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		dbms       DBMSType
+		expected   string
+		parameters []Parameter
+	}{
+		{
+			name:     "postgres",
+			input:    "SELECT * FROM users WHERE name = 'it''s' AND active = true AND deleted_at = NULL",
+			dbms:     DBMSPostgres,
+			expected: "SELECT * FROM users WHERE name = $1 AND active = $2 AND deleted_at = $3",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "$1", Type: ParameterTypeString, Value: "it's", Offset: 33},
+				{Position: 2, Placeholder: "$2", Type: ParameterTypeBoolean, Value: "true", Offset: 54},
+				{Position: 3, Placeholder: "$3", Type: ParameterTypeNull, Value: "NULL", Offset: 76},
+			},
+		},
+		{
+			name:     "mysql in list",
+			input:    "SELECT * FROM t WHERE id IN (1, -2, 3.5) AND s = 'a\\'b'",
+			dbms:     DBMSMySQL,
+			expected: "SELECT * FROM t WHERE id IN (?, ?, ?) AND s = ?",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "?", Type: ParameterTypeNumber, Value: "1", Offset: 29},
+				{Position: 2, Placeholder: "?", Type: ParameterTypeNumber, Value: "-2", Offset: 32},
+				{Position: 3, Placeholder: "?", Type: ParameterTypeNumber, Value: "3.5", Offset: 36},
+				{Position: 4, Placeholder: "?", Type: ParameterTypeString, Value: "a'b", Offset: 49},
+			},
+		},
+		{
+			name:     "oracle",
+			input:    "UPDATE t SET a = 'x' WHERE id = 42",
+			dbms:     DBMSOracle,
+			expected: "UPDATE t SET a = :1 WHERE id = :2",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: ":1", Type: ParameterTypeString, Value: "x", Offset: 17},
+				{Position: 2, Placeholder: ":2", Type: ParameterTypeNumber, Value: "42", Offset: 32},
+			},
+		},
+		{
+			name:     "sql server with existing parameters",
+			input:    "SELECT * FROM t WHERE a = @p1 AND b = N'x'",
+			dbms:     DBMSSQLServer,
+			expected: "SELECT * FROM t WHERE a = @p1 AND b = @p2",
+			parameters: []Parameter{
+				{Position: 2, Placeholder: "@p2", Type: ParameterTypeString, Value: "x", Offset: 38},
+			},
+		},
+		{
+			name:     "dollar quoted strings",
+			input:    "SELECT $$a 'b'$$, $tag$c$tag$, E'd\\ne'",
+			dbms:     DBMSPostgres,
+			expected: "SELECT $1, $2, $3",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "$1", Type: ParameterTypeString, Value: "a 'b'", Offset: 7},
+				{Position: 2, Placeholder: "$2", Type: ParameterTypeString, Value: "c", Offset: 18},
+				{Position: 3, Placeholder: "$3", Type: ParameterTypeString, Value: "d\ne", Offset: 31},
+			},
+		},
+		{
+			name:     "subtraction of a signed number",
+			input:    "SELECT a -1, b - -2, -3",
+			dbms:     DBMSPostgres,
+			expected: "SELECT a -$1, b - $2, $3",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "$1", Type: ParameterTypeNumber, Value: "1", Offset: 10},
+				{Position: 2, Placeholder: "$2", Type: ParameterTypeNumber, Value: "-2", Offset: 17},
+				{Position: 3, Placeholder: "$3", Type: ParameterTypeNumber, Value: "-3", Offset: 21},
+			},
+		},
+		{
+			name:       "literals that cannot be bound",
+			input:      "SELECT * FROM t WHERE a IS NULL AND b IS NOT TRUE AND c = x'ff' AND d > DATE '2024-01-01' AND e < now() - INTERVAL '1 day' AND f = TIMESTAMP WITH TIME ZONE '2024-01-01 00:00:00+00' AND g = :1",
+			dbms:       DBMSPostgres,
+			expected:   "SELECT * FROM t WHERE a IS NULL AND b IS NOT TRUE AND c = x'ff' AND d > DATE '2024-01-01' AND e < now() - INTERVAL '1 day' AND f = TIMESTAMP WITH TIME ZONE '2024-01-01 00:00:00+00' AND g = :1",
+			parameters: []Parameter{},
+		},
+		{
+			name:     "at time zone",
+			input:    "SELECT ts AT TIME ZONE 'UTC' FROM t",
+			dbms:     DBMSPostgres,
+			expected: "SELECT ts AT TIME ZONE $1 FROM t",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "$1", Type: ParameterTypeString, Value: "UTC", Offset: 23},
+			},
+		},
+		{
+			name:       "type modifiers",
+			input:      "CREATE TABLE t (a VARCHAR(50), b NUMERIC(10, 2), c CHARACTER VARYING (10))",
+			dbms:       DBMSPostgres,
+			expected:   "CREATE TABLE t (a VARCHAR(50), b NUMERIC(10, 2), c CHARACTER VARYING (10))",
+			parameters: []Parameter{},
+		},
+		{
+			name:     "cast type modifier",
+			input:    "SELECT CAST(@x AS NVARCHAR(10)) FROM t WHERE id = 5",
+			dbms:     DBMSSQLServer,
+			expected: "SELECT CAST(@x AS NVARCHAR(10)) FROM t WHERE id = @p1",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "@p1", Type: ParameterTypeNumber, Value: "5", Offset: 50},
+			},
+		},
+		{
+			name:     "ordinals",
+			input:    "SELECT a, b, count(*) FROM t WHERE c = 3 GROUP BY 1, 2 ORDER BY 1 DESC, 2 LIMIT 10, 20",
+			dbms:     DBMSMySQL,
+			expected: "SELECT a, b, count(*) FROM t WHERE c = ? GROUP BY 1, 2 ORDER BY 1 DESC, 2 LIMIT ?, ?",
+			parameters: []Parameter{
+				{Position: 1, Placeholder: "?", Type: ParameterTypeNumber, Value: "3", Offset: 39},
+				{Position: 2, Placeholder: "?", Type: ParameterTypeNumber, Value: "10", Offset: 80},
+				{Position: 3, Placeholder: "?", Type: ParameterTypeNumber, Value: "20", Offset: 84},
+			},
+		},
+		{
+			name:       "ddl defaults",
+			input:      "CREATE TABLE t (a TEXT DEFAULT 'x', b INT DEFAULT -1, c BIT DEFAULT (0))",
+			dbms:       DBMSSQLServer,
+			expected:   "CREATE TABLE t (a TEXT DEFAULT 'x', b INT DEFAULT -1, c BIT DEFAULT (0))",
+			parameters: []Parameter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameterized, parameters := Parameterize(tt.input, WithDBMS(tt.dbms))
+			assert.Equal(t, tt.expected, parameterized)
+			assert.Equal(t, tt.parameters, parameters)
+		})
+	}
+}
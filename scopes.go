@@ -0,0 +1,269 @@
+package sqllexer
+
+import (
+	"strings"
+)
+
+// ScopeKind is the kind of a query scope
+type ScopeKind string
+
+const (
+	ScopeKindStatement ScopeKind = "statement" // top-level statement
+	ScopeKindCTE       ScopeKind = "cte"       // body of a common table expression, e.g. WITH x AS (...)
+	ScopeKindSubquery  ScopeKind = "subquery"  // parenthesized subquery, e.g. a derived table or IN (SELECT ...)
+)
+
+// Scope is a node of the scope tree of a query.
+// Tables are the tables read or written directly in the scope, CTEs are the
+// common table expressions it references and Scopes are its nested scopes.
+type Scope struct {
+	Kind   ScopeKind `json:"kind"`
+	Name   string    `json:"name,omitempty"` // name of the CTE
+	Tables []string  `json:"tables"`
+	CTEs   []string  `json:"ctes,omitempty"`
+	Scopes []*Scope  `json:"scopes,omitempty"`
+}
+
+type withState int
+
+const (
+	withStateNone       withState = iota
+	withStateExpectName           // after WITH [RECURSIVE] or a comma between CTEs
+	withStateAfterName            // after the CTE name, e.g. WITH x [(a, b)] AS [NOT MATERIALIZED]
+	withStateAfterBody            // after the CTE body, a comma declares another CTE
+)
+
+// scopeFrame is a parenthesized expression or a top-level statement
+type scopeFrame struct {
+	scope       *Scope              // nil if scopes are not collected or the parentheses don't hold a subquery
+	cteName     string              // name of the CTE whose body the frame holds
+	ctes        map[string]struct{} // CTEs declared by the WITH clause of the frame
+	inTableList bool                // table list state of the frame, saved while a nested frame is read
+	firstToken  bool                // the next value token is the first one of the frame
+
+	with       withState
+	pendingCTE string // CTE declared, waiting for its body
+	afterAS    bool   // AS seen after the CTE name, the next parenthesis opens the body
+}
+
+// scopeStack tracks the nesting of subqueries and CTEs of a query,
+// so CTE names are only resolved where they are visible and table lists
+// survive derived tables, e.g. FROM a, (SELECT * FROM b) x, c
+type scopeStack struct {
+	collect     bool // build the scope tree
+	nested      bool // track the nesting of the query, see readFlat otherwise
+	frames      []scopeFrame
+	flat        scopeFrame // the frame of the whole query when the nesting is not tracked
+	roots       []*Scope
+	inTableList bool // the current token is read in a comma separated table list
+	ctes        int  // number of CTE bodies read
+	size        int
+}
+
+// read reads the next value token and reports whether it declares a CTE name
+func (s *scopeStack) read(token *Token) bool {
+	if len(s.frames) == 0 {
+		// room for a few nested frames, so that most queries allocate the stack once
+		s.frames = make([]scopeFrame, 1, 4)
+		s.frames[0] = scopeFrame{firstToken: true}
+	}
+	frame := s.current()
+	firstToken := frame.firstToken
+	frame.firstToken = false
+
+	if token.Type == PUNCTUATION && token.Value == ";" {
+		if len(s.frames) > 1 || !firstToken {
+			// CTEs declared by a statement remain excluded from the tables of the next ones
+			s.frames = append(s.frames[:0], scopeFrame{ctes: s.frames[0].ctes, firstToken: true})
+			s.inTableList = false
+		} else {
+			frame.firstToken = true
+		}
+		return false
+	}
+	if firstToken && len(s.frames) == 1 {
+		s.attach(frame, ScopeKindStatement, "")
+	}
+
+	if token.Type == PUNCTUATION {
+		switch token.Value {
+		case "(":
+			s.open(frame)
+			return false
+		case ")":
+			s.close()
+			return false
+		}
+	}
+
+	if firstToken && len(s.frames) > 1 && frame.scope == nil && (token.Type == CTE_INDICATOR || isStatementVerb(token.Value)) {
+		s.attach(frame, ScopeKindSubquery, "")
+	}
+
+	switch frame.with {
+	case withStateExpectName:
+		if token.Type == KEYWORD && strings.EqualFold(token.Value, "RECURSIVE") {
+			return false
+		}
+		if token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION {
+			// a CTE name followed by its column list is lexed as a function, e.g. WITH x(a, b) AS
+			name := token.Value
+			if token.Type == QUOTED_IDENT {
+				name = trimQuotes(token)
+			}
+			frame.pendingCTE = name
+			frame.afterAS = false
+			frame.with = withStateAfterName
+			// a CTE is visible in its own body, recursive CTEs don't always require RECURSIVE
+			frame.declare(name)
+			return true
+		}
+		frame.with = withStateNone
+	case withStateAfterName:
+		switch {
+		case token.Type == ALIAS_INDICATOR:
+			frame.afterAS = true
+		case frame.afterAS && (strings.EqualFold(token.Value, "NOT") || strings.EqualFold(token.Value, "MATERIALIZED")):
+		default:
+			frame.with = withStateNone
+		}
+	case withStateAfterBody:
+		if token.Type == PUNCTUATION && token.Value == "," {
+			frame.with = withStateExpectName
+			return false
+		}
+		frame.with = withStateNone
+	}
+
+	if token.Type == CTE_INDICATOR {
+		frame.with = withStateExpectName
+	}
+	return false
+}
+
+// readFlat reads the value token following WITH without tracking the nesting of the query and
+// reports whether it declares a CTE name, which is then excluded from the tables of the whole query
+func (s *scopeStack) readFlat(token *Token) bool {
+	if token.Type != IDENT && token.Type != QUOTED_IDENT && token.Type != FUNCTION {
+		return false
+	}
+	name := token.Value
+	if token.Type == QUOTED_IDENT {
+		name = trimQuotes(token)
+	}
+	s.flat.declare(name)
+	return true
+}
+
+// open opens a frame for the parenthesis read in frame
+func (s *scopeStack) open(frame *scopeFrame) {
+	frame.inTableList = s.inTableList
+	s.inTableList = false
+	if frame.with == withStateAfterName && frame.afterAS {
+		name := frame.pendingCTE
+		frame.pendingCTE = ""
+		frame.with = withStateNone
+		s.ctes++
+		s.push(ScopeKindCTE, name)
+		return
+	}
+	if frame.with != withStateAfterName {
+		// e.g. SQL Server table hints WITH (NOLOCK)
+		frame.with = withStateNone
+	}
+	// the parentheses turn into a subquery scope when they start with a statement
+	s.frames = append(s.frames, scopeFrame{firstToken: true})
+}
+
+// close closes the innermost frame, unbalanced parentheses are ignored
+func (s *scopeStack) close() {
+	if len(s.frames) <= 1 {
+		return
+	}
+	closed := s.frames[len(s.frames)-1]
+	s.frames = s.frames[:len(s.frames)-1]
+	parent := s.current()
+	if closed.cteName != "" {
+		parent.with = withStateAfterBody
+	}
+	s.inTableList = parent.inTableList
+}
+
+func (s *scopeStack) push(kind ScopeKind, cteName string) {
+	s.frames = append(s.frames, scopeFrame{cteName: cteName, firstToken: true})
+	s.attach(s.current(), kind, cteName)
+	s.inTableList = false
+}
+
+// attach creates the scope of frame and adds it to the closest enclosing scope
+func (s *scopeStack) attach(frame *scopeFrame, kind ScopeKind, name string) {
+	if !s.collect {
+		return
+	}
+	frame.scope = &Scope{Kind: kind, Name: strings.Clone(name), Tables: []string{}}
+	s.size += len(name)
+	for i := len(s.frames) - 2; i >= 0; i-- {
+		if parent := s.frames[i].scope; parent != nil {
+			parent.Scopes = append(parent.Scopes, frame.scope)
+			return
+		}
+	}
+	s.roots = append(s.roots, frame.scope)
+}
+
+func (s *scopeStack) current() *scopeFrame {
+	return &s.frames[len(s.frames)-1]
+}
+
+// isCTE reports whether name references a CTE visible in the current frame
+func (s *scopeStack) isCTE(name string) bool {
+	if _, ok := s.flat.ctes[name]; ok {
+		return true
+	}
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		if _, ok := s.frames[i].ctes[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addTable adds a table to the innermost scope
+func (s *scopeStack) addTable(table string) {
+	if scope := s.innermost(); scope != nil {
+		s.add(table, &scope.Tables)
+	}
+}
+
+// addCTE adds a CTE reference to the innermost scope
+func (s *scopeStack) addCTE(name string) {
+	if scope := s.innermost(); scope != nil {
+		s.add(name, &scope.CTEs)
+	}
+}
+
+func (s *scopeStack) add(value string, slice *[]string) {
+	for _, v := range *slice {
+		if v == value {
+			return
+		}
+	}
+	*slice = append(*slice, strings.Clone(value))
+	s.size += len(value)
+}
+
+func (s *scopeStack) innermost() *Scope {
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		if s.frames[i].scope != nil {
+			return s.frames[i].scope
+		}
+	}
+	return nil
+}
+
+func (f *scopeFrame) declare(name string) {
+	if f.ctes == nil {
+		f.ctes = make(map[string]struct{}, 2)
+	}
+	f.ctes[name] = struct{}{}
+}
@@ -0,0 +1,141 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerScopeTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		tables []string
+	}{
+		{
+			name:   "table list after derived table",
+			input:  "SELECT * FROM a, (SELECT * FROM b) x, c WHERE a.id = c.id",
+			tables: []string{"a", "b", "c"},
+		},
+		{
+			name:   "cte name reused as table outside of its scope",
+			input:  "SELECT * FROM (WITH t AS (SELECT * FROM src) SELECT * FROM t) x JOIN t ON x.id = t.id",
+			tables: []string{"src", "t"},
+		},
+		{
+			name:   "cte list",
+			input:  "WITH a AS (SELECT * FROM users), b AS (SELECT * FROM a JOIN orders ON a.id = orders.user_id) SELECT * FROM b",
+			tables: []string{"users", "orders"},
+		},
+		{
+			name:   "cte with column list and materialization",
+			input:  "WITH RECURSIVE a (id) AS NOT MATERIALIZED (SELECT id FROM users UNION ALL SELECT id FROM a) SELECT * FROM a",
+			tables: []string{"users"},
+		},
+		{
+			name:   "insert column list",
+			input:  "INSERT INTO t1 (a, b) SELECT a, b FROM t2",
+			tables: []string{"t1", "t2"},
+		},
+	}
+
+	// the tables are nesting-aware when the scopes are tracked
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectScopes(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tables, statementMetadata.Tables)
+		})
+	}
+}
+
+func TestNormalizerCollectScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []*Scope
+	}{
+		{
+			name:  "cte lineage",
+			input: "WITH recent AS (SELECT * FROM orders WHERE id NOT IN (SELECT order_id FROM refunds)), totals AS (SELECT user_id, SUM(amount) FROM recent GROUP BY user_id) SELECT * FROM totals JOIN users ON users.id = totals.user_id",
+			expected: []*Scope{
+				{
+					Kind:   ScopeKindStatement,
+					Tables: []string{"users"},
+					CTEs:   []string{"totals"},
+					Scopes: []*Scope{
+						{
+							Kind:   ScopeKindCTE,
+							Name:   "recent",
+							Tables: []string{"orders"},
+							Scopes: []*Scope{
+								{Kind: ScopeKindSubquery, Tables: []string{"refunds"}},
+							},
+						},
+						{
+							Kind:   ScopeKindCTE,
+							Name:   "totals",
+							Tables: []string{},
+							CTEs:   []string{"recent"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "derived table",
+			input: "SELECT * FROM a, (SELECT * FROM b WHERE b.x IN (1, 2)) x, c",
+			expected: []*Scope{
+				{
+					Kind:   ScopeKindStatement,
+					Tables: []string{"a", "c"},
+					Scopes: []*Scope{
+						{Kind: ScopeKindSubquery, Tables: []string{"b"}},
+					},
+				},
+			},
+		},
+		{
+			name:  "statements",
+			input: "UPDATE t1 SET a = (SELECT MAX(a) FROM t2); DELETE FROM t3;",
+			expected: []*Scope{
+				{
+					Kind:   ScopeKindStatement,
+					Tables: []string{"t1"},
+					Scopes: []*Scope{
+						{Kind: ScopeKindSubquery, Tables: []string{"t2"}},
+					},
+				},
+				{Kind: ScopeKindStatement, Tables: []string{"t3"}},
+			},
+		},
+		{
+			name:  "unbalanced parentheses",
+			input: "SELECT * FROM a) JOIN (SELECT * FROM b",
+			expected: []*Scope{
+				{
+					Kind:   ScopeKindStatement,
+					Tables: []string{"a"},
+					Scopes: []*Scope{
+						{Kind: ScopeKindSubquery, Tables: []string{"b"}},
+					},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectScopes(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, statementMetadata.Scopes)
+		})
+	}
+}
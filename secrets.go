@@ -0,0 +1,170 @@
+package sqllexer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SecretRule is a pattern of secret redacted by the SecretScanner
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate optionally filters the matches of Pattern, e.g. with a checksum
+	Validate func(match string) bool
+}
+
+var defaultSecretRules = []SecretRule{
+	{
+		Name:    "aws_access_key_id",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`),
+	},
+	{
+		Name:     "credit_card",
+		Pattern:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Validate: isLuhnValid,
+	},
+	{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+}
+
+// DefaultSecretRules returns the built-in secret rules: AWS access key IDs, JWTs,
+// credit card numbers passing the Luhn checksum and emails
+func DefaultSecretRules() []SecretRule {
+	rules := make([]SecretRule, len(defaultSecretRules))
+	copy(rules, defaultSecretRules)
+	return rules
+}
+
+type secretScannerConfig struct {
+	DefaultRules bool
+	Rules        []SecretRule
+}
+
+type secretScannerOption func(*secretScannerConfig)
+
+// WithDefaultSecretRules enables the built-in secret rules, enabled by default
+func WithDefaultSecretRules(defaultRules bool) secretScannerOption {
+	return func(c *secretScannerConfig) {
+		c.DefaultRules = defaultRules
+	}
+}
+
+// WithSecretRules adds user supplied secret rules, checked after the built-in ones
+func WithSecretRules(rules []SecretRule) secretScannerOption {
+	return func(c *secretScannerConfig) {
+		c.Rules = append(c.Rules, rules...)
+	}
+}
+
+// SecretScanner redacts secrets matching its rules in literals and comments
+type SecretScanner struct {
+	rules []SecretRule
+}
+
+func NewSecretScanner(opts ...secretScannerOption) *SecretScanner {
+	config := &secretScannerConfig{DefaultRules: true}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	scanner := &SecretScanner{}
+	if config.DefaultRules {
+		scanner.rules = append(scanner.rules, defaultSecretRules...)
+	}
+	scanner.rules = append(scanner.rules, config.Rules...)
+	return scanner
+}
+
+// Redact replaces the secrets in value with ? and returns the names of the rules that fired
+func (s *SecretScanner) Redact(value string) (string, []string) {
+	var fired []string
+	for _, rule := range s.rules {
+		matched := false
+		value = rule.Pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+			matched = true
+			return StringPlaceholder
+		})
+		if matched {
+			fired = append(fired, rule.Name)
+		}
+	}
+	return value, fired
+}
+
+// SecretFinding is a literal or comment of the query in which a secret rule fired
+type SecretFinding struct {
+	Rule string `json:"rule"`
+	// Offset is the byte offset of the literal or comment in the input
+	Offset int `json:"offset"`
+}
+
+// Scan redacts the secrets in the literals and comments of the input, leaving the rest of the query
+// as it is, and returns the rules that fired
+func (s *SecretScanner) Scan(input string, lexerOpts ...lexerOption) (string, []SecretFinding) {
+	var builder strings.Builder
+	builder.Grow(len(input))
+	findings := []SecretFinding{}
+	offset := 0
+	lexer := New(input, lexerOpts...)
+	for token := range lexer.All() {
+		value := token.Value
+		if mayHoldSecret(&token) {
+			var fired []string
+			value, fired = s.Redact(value)
+			for _, rule := range fired {
+				findings = append(findings, SecretFinding{Rule: rule, Offset: offset})
+			}
+		}
+		builder.WriteString(value)
+		offset += len(token.Value)
+	}
+	return builder.String(), findings
+}
+
+// redactToken redacts the secrets in a literal or comment token and returns the names of the rules that fired
+func (s *SecretScanner) redactToken(token *Token) []string {
+	if !mayHoldSecret(token) {
+		return nil
+	}
+	var fired []string
+	token.Value, fired = s.Redact(token.Value)
+	return fired
+}
+
+// mayHoldSecret reports whether the token is a literal or a comment
+func mayHoldSecret(token *Token) bool {
+	switch token.Type {
+	case STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, NUMBER, COMMENT, MULTILINE_COMMENT:
+		return true
+	}
+	return false
+}
+
+// isLuhnValid reports whether the digits of a card number pass the Luhn checksum
+func isLuhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if !isDigit(rune(number[i])) {
+			continue
+		}
+		digit := int(number[i] - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
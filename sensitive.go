@@ -0,0 +1,299 @@
+package sqllexer
+
+import (
+	"path"
+	"strings"
+)
+
+// insertState is the position of the sensitive column context in an INSERT statement
+type insertState int
+
+const (
+	insertNone insertState = iota
+	insertTable
+	insertColumns
+	insertAfterColumns
+	insertValues
+)
+
+// sensitiveLiteral is a literal of the query and whether it is tied to a sensitive column
+type sensitiveLiteral struct {
+	value     string
+	sensitive bool
+}
+
+// sensitiveColumnContext tells whether the literals of a query are compared to, inserted into or
+// assigned to a sensitive column, see WithSensitiveColumns.
+// The query is read ahead once, so that the literals on both sides of a comparison are known, e.g. 'x' = password.
+// It fails closed: every literal of an expression compared to a sensitive column is obfuscated, and so is
+// any literal it cannot match with the query read ahead.
+// The zero value is disabled and keeps nothing.
+type sensitiveColumnContext struct {
+	patterns []string
+	// literals holds the literals of the query in order
+	literals []sensitiveLiteral
+	// next is the index of the next literal to keep or obfuscate
+	next int
+
+	insert insertState
+	// columns tells by position whether the columns of the INSERT are sensitive
+	columns []bool
+	// column is the position of the value in the current VALUES tuple
+	column int
+	// depth is the parenthesis depth in the columns or VALUES of the INSERT
+	depth int
+}
+
+func (o *Obfuscator) newSensitiveColumnContext(input string, lexerOpts ...lexerOption) sensitiveColumnContext {
+	c := sensitiveColumnContext{patterns: o.config.SensitiveColumns}
+	if len(c.patterns) == 0 {
+		return c
+	}
+
+	var tokens []Token
+	for token := range New(input, lexerOpts...).ValueTokens() {
+		tokens = append(tokens, token)
+	}
+	sensitive := make([]bool, len(tokens))
+	for i := range tokens {
+		token := &tokens[i]
+		if isSensitiveLiteralType(token) && c.insert == insertValues && c.depth > 0 {
+			// values are matched with their column by position
+			sensitive[i] = sensitive[i] || c.column < len(c.columns) && c.columns[c.column]
+		}
+		c.updateInsert(token)
+		c.markComparison(tokens, i, sensitive)
+	}
+	for i := range tokens {
+		if isSensitiveLiteralType(&tokens[i]) {
+			c.literals = append(c.literals, sensitiveLiteral{value: tokens[i].Value, sensitive: sensitive[i]})
+		}
+	}
+	return c
+}
+
+// keep reports whether the literal token is kept as is, that is when sensitive columns are configured
+// and the literal is not tied to one of them
+func (c *sensitiveColumnContext) keep(token *Token) bool {
+	if len(c.patterns) == 0 || !isSensitiveLiteralType(token) {
+		return false
+	}
+	if c.next >= len(c.literals) || c.literals[c.next].value != token.Value {
+		// not a literal of the query read ahead, e.g. a literal of an optimizer hint
+		return false
+	}
+	literal := c.literals[c.next]
+	c.next++
+	return !literal.sensitive
+}
+
+// markComparison marks the literals of both sides of the comparison at index i when either side
+// refers to a sensitive column, e.g. password = 'x', 'x' = password, lower(password) LIKE lower('x%'),
+// ssn NOT BETWEEN '1' AND '2' or email IN ('a', 'b')
+func (c *sensitiveColumnContext) markComparison(tokens []Token, i int, sensitive []bool) {
+	if !isSensitiveOperator(&tokens[i]) {
+		return
+	}
+	end := i
+	if tokens[i].Type != OPERATOR && i > 0 && strings.EqualFold(tokens[i-1].Value, "NOT") {
+		// NOT LIKE, NOT IN, NOT BETWEEN
+		end = i - 1
+	}
+	start := expressionStart(tokens, end)
+	stop := expressionEnd(tokens, i+1, strings.EqualFold(tokens[i].Value, "BETWEEN"))
+	if !c.hasSensitiveColumn(tokens[start:end]) && !c.hasSensitiveColumn(tokens[i+1:stop]) {
+		return
+	}
+	for j := start; j < stop; j++ {
+		if isSensitiveLiteralType(&tokens[j]) {
+			sensitive[j] = true
+		}
+	}
+}
+
+// hasSensitiveColumn reports whether one of the tokens is a sensitive column
+func (c *sensitiveColumnContext) hasSensitiveColumn(tokens []Token) bool {
+	for i := range tokens {
+		if c.isSensitiveColumn(&tokens[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateInsert matches the values of INSERT INTO t (cols) VALUES (...), (...) with their column by position
+func (c *sensitiveColumnContext) updateInsert(token *Token) {
+	if token.Type == COMMAND {
+		c.insert = insertNone
+		switch strings.ToUpper(token.Value) {
+		case "INSERT", "REPLACE", "UPSERT":
+			c.insert = insertTable
+			c.columns = c.columns[:0]
+		}
+		return
+	}
+	switch c.insert {
+	case insertTable:
+		if token.Type == PUNCTUATION && token.Value == "(" {
+			c.insert = insertColumns
+			c.depth = 1
+		} else if strings.EqualFold(token.Value, "VALUES") || strings.EqualFold(token.Value, "SELECT") {
+			// no column list
+			c.insert = insertNone
+		}
+	case insertColumns:
+		switch {
+		case token.Type == PUNCTUATION && token.Value == "(":
+			c.depth++
+		case token.Type == PUNCTUATION && token.Value == ")":
+			if c.depth--; c.depth == 0 {
+				c.insert = insertAfterColumns
+			}
+		case c.depth == 1 && (token.Type == IDENT || token.Type == QUOTED_IDENT):
+			c.columns = append(c.columns, c.isSensitiveColumn(token))
+		}
+	case insertAfterColumns:
+		c.insert = insertNone
+		if strings.EqualFold(token.Value, "VALUES") || strings.EqualFold(token.Value, "VALUE") {
+			c.insert = insertValues
+		}
+	case insertValues:
+		switch {
+		case token.Type == PUNCTUATION && token.Value == "(":
+			if c.depth == 0 {
+				c.column = 0
+			}
+			c.depth++
+		case token.Type == PUNCTUATION && token.Value == ")":
+			c.depth--
+		case token.Type == PUNCTUATION && token.Value == ",":
+			if c.depth == 1 {
+				c.column++
+			}
+		case c.depth == 0:
+			// ON CONFLICT, RETURNING...
+			c.insert = insertNone
+		}
+	}
+}
+
+// isSensitiveColumn reports whether the token is a column matching one of the patterns,
+// ignoring its qualifier, quotes and case
+func (c *sensitiveColumnContext) isSensitiveColumn(token *Token) bool {
+	if token.Type != IDENT && token.Type != QUOTED_IDENT {
+		return false
+	}
+	name := token.Value
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(strings.Trim(name, "\"`[]"))
+	for _, pattern := range c.patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// expressionStart returns the index of the first token of the expression ending before index end,
+// e.g. lower('x') in WHERE lower('x') = password
+func expressionStart(tokens []Token, end int) int {
+	depth := 0
+	for j := end - 1; j >= 0; j-- {
+		token := &tokens[j]
+		if token.Type == PUNCTUATION {
+			switch token.Value {
+			case ")", "]":
+				depth++
+			case "(", "[":
+				if depth == 0 {
+					return j + 1
+				}
+				depth--
+			case ",", ";":
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		} else if depth == 0 && isExpressionBoundary(tokens, j) {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+// expressionEnd returns the index following the last token of the expression starting at index start,
+// e.g. CAST('x' AS text) in WHERE password = CAST('x' AS text) AND id = 1.
+// The first AND is part of the expression following BETWEEN.
+func expressionEnd(tokens []Token, start int, between bool) int {
+	depth := 0
+	for j := start; j < len(tokens); j++ {
+		token := &tokens[j]
+		if token.Type == PUNCTUATION {
+			switch token.Value {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				if depth == 0 {
+					return j
+				}
+				depth--
+			case ",", ";":
+				if depth == 0 {
+					return j
+				}
+			}
+		} else if depth == 0 && isExpressionBoundary(tokens, j) {
+			if between && strings.EqualFold(token.Value, "AND") {
+				between = false
+				continue
+			}
+			return j
+		}
+	}
+	return len(tokens)
+}
+
+// isExpressionBoundary reports whether the token at index j ends an expression, e.g. AND, WHERE or THEN
+func isExpressionBoundary(tokens []Token, j int) bool {
+	token := &tokens[j]
+	switch token.Type {
+	case COMMAND, ALIAS_INDICATOR, CTE_INDICATOR, PROC_INDICATOR:
+		return true
+	case KEYWORD, IDENT:
+	default:
+		return false
+	}
+	switch strings.ToUpper(token.Value) {
+	case "AND", "OR", "WHERE", "SELECT", "GROUP", "ORDER", "HAVING", "LIMIT", "OFFSET", "RETURNING",
+		"UNION", "INTERSECT", "EXCEPT", "ON", "USING", "SET", "WHEN", "THEN", "ELSE", "END", "JOIN", "VALUES", "INTO", "AS":
+		return true
+	case "FROM":
+		// IS DISTINCT FROM
+		return j == 0 || !strings.EqualFold(tokens[j-1].Value, "DISTINCT")
+	}
+	return false
+}
+
+// isSensitiveOperator reports whether the token compares or assigns its operands
+func isSensitiveOperator(token *Token) bool {
+	switch token.Type {
+	case OPERATOR:
+		return isComparisonOperator(token.Value) || token.Value == ":="
+	case KEYWORD, IDENT:
+		switch strings.ToUpper(token.Value) {
+		case "LIKE", "ILIKE", "RLIKE", "REGEXP", "SIMILAR", "GLOB", "IN", "BETWEEN", "IS":
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitiveLiteralType(token *Token) bool {
+	switch token.Type {
+	case NUMBER, STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, BOOLEAN, NULL:
+		return true
+	}
+	return false
+}
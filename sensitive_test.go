@@ -0,0 +1,92 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscatorSensitiveColumns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		dbms     DBMSType
+	}{
+		{
+			input:    "SELECT * FROM users WHERE u.email = 'bob@example.com' AND id = 42 AND name LIKE 'bob%'",
+			expected: "SELECT * FROM users WHERE u.email = ? AND id = 42 AND name LIKE 'bob%'",
+		},
+		{
+			input:    "SELECT * FROM users WHERE password NOT LIKE 'x%' AND user_ssn<>'123-45-6789'",
+			expected: "SELECT * FROM users WHERE password NOT LIKE ? AND user_ssn<>?",
+		},
+		{
+			input:    "UPDATE users SET password = 'hunter2', name = 'bob' WHERE id = 1",
+			expected: "UPDATE users SET password = ?, name = 'bob' WHERE id = 1",
+		},
+		{
+			// values are matched with their column by position
+			input:    `INSERT INTO users (id, email, "Password", name) VALUES (1, 'bob@example.com', LOWER('pw'), 'bob'), (2, 'alice@example.com', 'pw2', 'alice')`,
+			expected: `INSERT INTO users (id, email, "Password", name) VALUES (1, ?, LOWER(?), 'bob'), (2, ?, ?, 'alice')`,
+		},
+		{
+			input:    "INSERT INTO users (id, email) VALUES (1, 'bob@example.com') ON DUPLICATE KEY UPDATE `email` = 'alice@example.com', id = 2",
+			expected: "INSERT INTO users (id, email) VALUES (1, ?) ON DUPLICATE KEY UPDATE `email` = ?, id = 2",
+			dbms:     DBMSMySQL,
+		},
+		{
+			input:    "SELECT * FROM users WHERE email IN ('a@example.com', 'b@example.com') AND id IN (1, 2)",
+			expected: "SELECT * FROM users WHERE email IN (?, ?) AND id IN (1, 2)",
+		},
+		{
+			input:    "SELECT * FROM users WHERE ssn NOT BETWEEN '100' AND '200' AND age BETWEEN 18 AND 30",
+			expected: "SELECT * FROM users WHERE ssn NOT BETWEEN ? AND ? AND age BETWEEN 18 AND 30",
+		},
+		{
+			input:    "SELECT * FROM users WHERE 'hunter2' = password AND id = 1",
+			expected: "SELECT * FROM users WHERE ? = password AND id = 1",
+		},
+		{
+			input:    "SELECT * FROM users WHERE password = N'hunter2' OR password = E'hunter2'",
+			expected: "SELECT * FROM users WHERE password = N? OR password = E?",
+		},
+		{
+			input:    "SELECT * FROM users WHERE password = CAST('hunter2' AS text) AND name = 'bob'",
+			expected: "SELECT * FROM users WHERE password = CAST(? AS text) AND name = 'bob'",
+		},
+		{
+			input:    "SELECT * FROM users WHERE password = ('hunter2') AND id = 1",
+			expected: "SELECT * FROM users WHERE password = (?) AND id = 1",
+		},
+		{
+			input:    "SELECT * FROM users WHERE password = lower('hunter2') AND lower(email) LIKE lower('BOB%')",
+			expected: "SELECT * FROM users WHERE password = lower(?) AND lower(email) LIKE lower(?)",
+		},
+		{
+			// without a column list, values cannot be matched with their column
+			input:    "INSERT INTO users VALUES (1, 'bob@example.com')",
+			expected: "INSERT INTO users VALUES (1, 'bob@example.com')",
+		},
+	}
+
+	obfuscator := NewObfuscator(
+		WithReplaceDigits(true),
+		WithSensitiveColumns([]string{"password", "*SSN*", "email"}),
+	)
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got := obfuscator.Obfuscate(tt.input, WithDBMS(tt.dbms))
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestObfuscateAndNormalizeSensitiveColumns(t *testing.T) {
+	obfuscator := NewObfuscator(WithSensitiveColumns([]string{"email"}))
+	normalizer := NewNormalizer()
+
+	got, _, err := ObfuscateAndNormalize("SELECT * FROM users WHERE email IN ('a@example.com', 'b@example.com') AND id = 42", obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE email IN ( ? ) AND id = 42", got)
+}
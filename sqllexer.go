@@ -1,6 +1,9 @@
 package sqllexer
 
 import (
+	"iter"
+	"slices"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -45,9 +48,23 @@ type Token struct {
 	hasDigits          bool
 	hasQuotes          bool           // private - only used by trimQuotes
 	isSimpleIdentifier bool           // true if quoted ident started with a letter and only used alphanumerics afterwards
+	isMalformed        bool           // true if the number is malformed, e.g. 1.2.3 or 1e
+	literalType        string         // the type of a typed string literal, e.g. date or jsonb
 	lastValueToken     LastValueToken // private - internal state
 }
 
+// LiteralType returns the type of a typed string literal, e.g. date for DATE '2024-01-01',
+// timestamp with time zone for TIMESTAMP WITH TIME ZONE '2024-01-01 00:00:00+00' or jsonb for '{}'::jsonb.
+// It is empty for other tokens, and unless the lexer is created WithTypedLiterals.
+func (t *Token) LiteralType() string {
+	return t.literalType
+}
+
+// IsMalformed reports whether the token is a malformed number, e.g. 1.2.3, 1e, 0x or 12abc
+func (t *Token) IsMalformed() bool {
+	return t.isMalformed
+}
+
 type LastValueToken struct {
 	Type               TokenType
 	Value              string
@@ -66,6 +83,9 @@ func (t *Token) getLastValueToken() *LastValueToken {
 
 type LexerConfig struct {
 	DBMS DBMSType `json:"dbms,omitempty"`
+	// TypedLiterals specifies whether typed string literals, such as DATE '2024-01-01' or '{}'::jsonb,
+	// are annotated with their type, see Token.LiteralType
+	TypedLiterals bool `json:"typed_literals,omitempty"`
 }
 
 type lexerOption func(*LexerConfig)
@@ -77,6 +97,22 @@ func WithDBMS(dbms DBMSType) lexerOption {
 	}
 }
 
+// WithTypedLiterals annotates typed string literals with their type, see Token.LiteralType.
+// The type name preceding a typed literal is then lexed as a KEYWORD.
+func WithTypedLiterals(typedLiterals bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.TypedLiterals = typedLiterals
+	}
+}
+
+// withTypedLiterals returns the lexer options with typed literals enabled if typed is true
+func withTypedLiterals(lexerOpts []lexerOption, typed bool) []lexerOption {
+	if !typed {
+		return lexerOpts
+	}
+	return append(slices.Clip(lexerOpts), WithTypedLiterals(true))
+}
+
 // SQL Lexer inspired from Rob Pike's talk on Lexical Scanning in Go
 type Lexer struct {
 	src                string // the input src string
@@ -84,10 +120,13 @@ type Lexer struct {
 	start              int    // the start position of the current token
 	config             *LexerConfig
 	token              *Token
-	hasQuotes          bool // true if any quotes in token
-	hasDigits          bool // true if the token has digits
-	isTableIndicator   bool // true if the token is a table indicator
-	isSimpleIdentifier bool // true if current quoted ident started with a letter and only used alphanumerics afterwards
+	hasQuotes          bool   // true if any quotes in token
+	hasDigits          bool   // true if the token has digits
+	isTableIndicator   bool   // true if the token is a table indicator
+	isSimpleIdentifier bool   // true if current quoted ident started with a letter and only used alphanumerics afterwards
+	isMalformed        bool   // true if the current number is malformed
+	literalType        string // the type of the upcoming typed string literal
+	typeWords          int    // the number of words left in the type name of the upcoming typed string literal
 }
 
 func New(input string, opts ...lexerOption) *Lexer {
@@ -103,11 +142,18 @@ func New(input string, opts ...lexerOption) *Lexer {
 }
 
 // Scan scans the next token and returns it.
+// The returned pointer refers to a token owned by the lexer and is overwritten
+// by the next call to Scan. Callers that need to keep a token around should
+// copy it (*token) or use All, ValueTokens or Tokens instead.
 func (s *Lexer) Scan() *Token {
 	ch := s.peek()
 	switch {
 	case isSpace(ch):
 		return s.scanWhitespace()
+	case (ch == 'x' || ch == 'X') && s.lookAhead(1) == '\'' && s.config.DBMS == DBMSMySQL:
+		return s.scanQuotedNumber(isHexDigit)
+	case (ch == 'b' || ch == 'B') && s.lookAhead(1) == '\'' && s.config.DBMS == DBMSMySQL:
+		return s.scanQuotedNumber(isBinaryDigit)
 	case isLetter(ch):
 		return s.scanIdentifier(ch)
 	case isDoubleQuote(ch):
@@ -196,6 +242,46 @@ func (s *Lexer) Scan() *Token {
 	}
 }
 
+// All returns an iterator over the remaining tokens of the input, excluding EOF.
+// Each token is yielded by value, so it stays valid after the iteration advances.
+// The token Value still references the input string, it is not cloned.
+func (s *Lexer) All() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			token := s.Scan()
+			if token.Type == EOF {
+				return
+			}
+			if !yield(*token) {
+				return
+			}
+		}
+	}
+}
+
+// ValueTokens returns an iterator like All that skips whitespace and comments.
+func (s *Lexer) ValueTokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for token := range s.All() {
+			if !isValueToken(&token) {
+				continue
+			}
+			if !yield(token) {
+				return
+			}
+		}
+	}
+}
+
+// Tokens scans the remaining input and returns a copy of every token, excluding EOF.
+func (s *Lexer) Tokens() []Token {
+	var tokens []Token
+	for token := range s.All() {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
 // lookAhead returns the rune n positions ahead of the cursor.
 func (s *Lexer) lookAhead(n int) rune {
 	pos := s.cursor + n
@@ -257,7 +343,7 @@ func (s *Lexer) matchAt(match []rune) bool {
 func (s *Lexer) scanNumberWithLeadingSign() *Token {
 	s.start = s.cursor
 	ch := s.next() // consume the leading sign
-	return s.scanDecimalNumber(ch)
+	return s.scanNumberic(ch)
 }
 
 func (s *Lexer) scanNumber(ch rune) *Token {
@@ -265,51 +351,125 @@ func (s *Lexer) scanNumber(ch rune) *Token {
 	return s.scanNumberic(ch)
 }
 
+// scanNumberic scans a number starting at ch, after the leading sign if any.
+// A number directly followed by letters, digits or dots, such as 1.2.3 or 12abc, is scanned as a single
+// malformed NUMBER so that its digits never leak into a neighbouring identifier.
+// In MySQL, where identifiers may start with digits, such a word without dots is an IDENT instead.
 func (s *Lexer) scanNumberic(ch rune) *Token {
-	s.start = s.cursor
 	if ch == '0' {
-		nextCh := s.lookAhead(1)
-		if nextCh == 'x' || nextCh == 'X' {
-			return s.scanHexNumber()
-		} else if nextCh >= '0' && nextCh <= '7' {
+		switch s.lookAhead(1) {
+		case 'x', 'X':
+			return s.scanRadixNumber(isHexDigit, s.config.DBMS == DBMSSQLServer) // 0x is an empty binary in SQL Server
+		case 'b', 'B':
+			return s.scanRadixNumber(isBinaryDigit, false)
+		case 'o', 'O':
+			if s.config.DBMS != DBMSMySQL {
+				return s.scanRadixNumber(isOctalDigit, false)
+			}
+		}
+		if s.config.DBMS == "" && isOctalDigit(s.lookAhead(1)) {
+			// without a dialect, a leading 0 is an octal number as in C.
+			// Every supported DBMS reads 0123 as the decimal 123.
 			return s.scanOctalNumber()
 		}
 	}
-
-	ch = s.next() // consume first digit
 	return s.scanDecimalNumber(ch)
 }
 
 func (s *Lexer) scanDecimalNumber(ch rune) *Token {
-	// scan digits
-	for isDigit(ch) || ch == '.' || isExpontent(ch) {
-		if isExpontent(ch) {
-			ch = s.next()
-			if isLeadingSign(ch) {
-				ch = s.next()
-			}
-		} else {
+	ch, digits, valid := s.scanDigits(ch, isDigit)
+	if ch == '.' {
+		var fraction, validFraction bool
+		ch, fraction, validFraction = s.scanDigits(s.next(), isDigit)
+		digits = digits || fraction
+		valid = valid && validFraction
+	}
+	valid = valid && digits
+	if isExpontent(ch) && digits {
+		ch = s.next()
+		if isLeadingSign(ch) {
 			ch = s.next()
 		}
+		var exponent, validExponent bool
+		ch, exponent, validExponent = s.scanDigits(ch, isDigit)
+		valid = valid && exponent && validExponent
 	}
-	return s.emit(NUMBER)
+	return s.emitNumber(ch, valid)
 }
 
-func (s *Lexer) scanHexNumber() *Token {
-	ch := s.nextBy(2) // consume 0x or 0X
-
-	for isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F') {
-		ch = s.next()
-	}
-	return s.emit(NUMBER)
+func (s *Lexer) scanRadixNumber(isRadixDigit func(rune) bool, allowEmpty bool) *Token {
+	ch := s.nextBy(2) // consume the 0x, 0b or 0o prefix
+	ch, digits, valid := s.scanDigits(ch, isRadixDigit)
+	return s.emitNumber(ch, valid && (digits || allowEmpty))
 }
 
 func (s *Lexer) scanOctalNumber() *Token {
 	ch := s.nextBy(2) // consume the leading 0 and number
 
-	for '0' <= ch && ch <= '7' {
+	for isOctalDigit(ch) {
+		ch = s.next()
+	}
+	return s.emitNumber(ch, true)
+}
+
+// scanDigits scans a run of digits, which may be separated by single underscores in PostgreSQL, e.g. 1_000_000.
+// It reports whether any digit was scanned and whether the underscores are well placed.
+func (s *Lexer) scanDigits(ch rune, isNumberDigit func(rune) bool) (next rune, digits bool, valid bool) {
+	valid = true
+	for {
+		switch {
+		case isNumberDigit(ch):
+			digits = true
+		case ch == '_' && s.config.DBMS == DBMSPostgres:
+			// an underscore must sit between two digits
+			valid = valid && digits && isNumberDigit(s.lookAhead(1))
+		default:
+			return ch, digits, valid
+		}
 		ch = s.next()
 	}
+}
+
+// emitNumber emits the number scanned so far, absorbing the letters, digits and dots directly following it
+func (s *Lexer) emitNumber(ch rune, valid bool) *Token {
+	if !isAlphaNumeric(ch) && !(ch == '.' && isDigit(s.lookAhead(1))) {
+		s.isMalformed = !valid
+		return s.emit(NUMBER)
+	}
+	for isAlphaNumeric(ch) || ch == '.' && isAlphaNumeric(s.lookAhead(1)) {
+		ch = s.nextBy(utf8.RuneLen(ch))
+	}
+	if s.config.DBMS == DBMSMySQL && !strings.ContainsRune(s.src[s.start:s.cursor], '.') {
+		// identifiers may start with digits in MySQL, e.g. 1abc
+		s.hasDigits = true
+		return s.emit(IDENT)
+	}
+	s.isMalformed = true
+	return s.emit(NUMBER)
+}
+
+// scanQuotedNumber scans the MySQL hex and bit literals x'ff' and b'0101'
+func (s *Lexer) scanQuotedNumber(isNumberDigit func(rune) bool) *Token {
+	s.start = s.cursor
+	ch := s.nextBy(2) // consume the x' or b' prefix
+	digits := 0
+	valid := true
+	// stop at the first character that cannot be part of the literal, so that an unterminated
+	// literal does not run to the next quote of the query
+	for ; isAlphaNumeric(ch); ch = s.next() {
+		valid = valid && isNumberDigit(ch)
+		digits++
+	}
+	if ch == '\'' {
+		s.next() // consume the closing quote
+	} else {
+		valid = false
+	}
+	if isNumberDigit('f') && digits%2 != 0 {
+		// hex literals hold whole bytes
+		valid = false
+	}
+	s.isMalformed = !valid
 	return s.emit(NUMBER)
 }
 
@@ -338,15 +498,18 @@ func (s *Lexer) scanStringWithDelimiter(delimiter rune) *Token {
 
 		if ch == delimiter {
 			s.next() // consume the closing quote
-			return s.emit(STRING)
+			if s.config.TypedLiterals && s.literalType == "" {
+				s.literalType = s.castType()
+			}
+			return s.emitString(STRING)
 		}
 	}
 	// Special case: if we ended with an escaped quote (e.g. ESCAPE '\')
 	if escapedQuote {
-		return s.emit(STRING)
+		return s.emitString(STRING)
 	}
 	// If we get here, we hit EOF before finding closing quote
-	return s.emit(INCOMPLETE_STRING)
+	return s.emitString(INCOMPLETE_STRING)
 }
 
 func (s *Lexer) scanIdentifier(ch rune) *Token {
@@ -401,6 +564,9 @@ func (s *Lexer) scanIdentifier(ch rune) *Token {
 	// If we found a complete keyword and next char is whitespace
 	if node.isEnd && (isPunctuation(ch) || isSpace(ch) || isMultiLineComment(ch, s.lookAhead(1)) || isEOF(ch)) {
 		s.cursor = pos + 1 // Include the last matched character
+		if s.config.TypedLiterals && s.isTypeName() {
+			return s.emit(KEYWORD)
+		}
 		s.isTableIndicator = node.isTableIndicator
 		return s.emit(node.tokenType)
 	}
@@ -418,9 +584,94 @@ func (s *Lexer) scanIdentifier(ch rune) *Token {
 	if ch == '(' {
 		return s.emit(FUNCTION)
 	}
+	if s.config.TypedLiterals && s.isTypeName() {
+		return s.emit(KEYWORD)
+	}
 	return s.emit(IDENT)
 }
 
+// isTypeName reports whether the word just scanned is part of the type name of a typed string literal,
+// such as DATE '2024-01-01' or TIMESTAMP WITH TIME ZONE '2024-01-01 00:00:00+00', and records its type
+func (s *Lexer) isTypeName() bool {
+	if s.typeWords > 0 {
+		// WITH TIME ZONE
+		s.typeWords--
+		return true
+	}
+	literalType := typedLiteralType(s.src[s.start:s.cursor])
+	if literalType == "" {
+		return false
+	}
+	pos := s.skipSpaces(s.cursor)
+	if literalType == "time" || literalType == "timestamp" {
+		if zone, end := s.matchTimeZone(pos); zone != "" {
+			literalType += " " + zone
+			s.typeWords = 3
+			pos = end
+		}
+	}
+	if !s.isStringStart(pos) {
+		s.typeWords = 0
+		return false
+	}
+	s.literalType = literalType
+	return true
+}
+
+// matchTimeZone matches WITH TIME ZONE or WITHOUT TIME ZONE followed by a string at pos,
+// and returns it lowercased with the position of the string
+func (s *Lexer) matchTimeZone(pos int) (string, int) {
+	var words [3]string
+	for i := range words {
+		end := pos
+		for end < len(s.src) && isAsciiLetter(rune(s.src[end])) {
+			end++
+		}
+		words[i] = s.src[pos:end]
+		pos = s.skipSpaces(end)
+	}
+	if (!strings.EqualFold(words[0], "WITH") && !strings.EqualFold(words[0], "WITHOUT")) ||
+		!strings.EqualFold(words[1], "TIME") || !strings.EqualFold(words[2], "ZONE") || !s.isStringStart(pos) {
+		return "", 0
+	}
+	return strings.ToLower(words[0]) + " time zone", pos
+}
+
+// castType returns the lowercased type of a Postgres cast such as ::jsonb or ::int[] at the cursor, or ""
+func (s *Lexer) castType() string {
+	if !s.matchAt([]rune("::")) {
+		return ""
+	}
+	start := s.cursor + 2
+	end := start
+	for end < len(s.src) && (isAsciiLetter(rune(s.src[end])) || s.src[end] == '_' || end > start && isDigit(rune(s.src[end]))) {
+		end++
+	}
+	if end == start {
+		return ""
+	}
+	for end+1 < len(s.src) && s.src[end] == '[' && s.src[end+1] == ']' {
+		end += 2
+	}
+	return strings.ToLower(s.src[start:end])
+}
+
+// skipSpaces returns the position of the first non-space character from pos
+func (s *Lexer) skipSpaces(pos int) int {
+	for pos < len(s.src) && isSpace(rune(s.src[pos])) {
+		pos++
+	}
+	return pos
+}
+
+// isStringStart reports whether a string literal starts at pos
+func (s *Lexer) isStringStart(pos int) bool {
+	if pos >= len(s.src) {
+		return false
+	}
+	return s.src[pos] == '\'' || s.config.DBMS == DBMSMySQL && s.src[pos] == '"'
+}
+
 func (s *Lexer) scanDoubleQuotedIdentifier(delimiter rune) *Token {
 	closingDelimiter := delimiter
 	if delimiter == '[' {
@@ -654,25 +905,32 @@ func (s *Lexer) scanUnknown() *Token {
 // Modify emit function to use positions and maintain links
 func (s *Lexer) emit(t TokenType) *Token {
 	tok := s.token
-	lastValueToken := tok.lastValueToken
-
-	// Zero other fields
-	*tok = Token{
-		Type:             t,
-		Value:            s.src[s.start:s.cursor],
-		isTableIndicator: s.isTableIndicator,
-		lastValueToken:   lastValueToken,
-	}
 
+	// Set every field but lastValueToken, field by field rather than copying a whole Token
+	tok.Type = t
+	tok.Value = s.src[s.start:s.cursor]
+	tok.isTableIndicator = s.isTableIndicator
 	tok.hasDigits = s.hasDigits
 	tok.hasQuotes = s.hasQuotes
 	tok.isSimpleIdentifier = s.isSimpleIdentifier
+	tok.isMalformed = s.isMalformed
+	tok.literalType = ""
 
 	// Reset lexer state
 	s.start = s.cursor
 	s.isTableIndicator = false
 	s.hasDigits = false
 	s.isSimpleIdentifier = false
+	s.isMalformed = false
+
+	return tok
+}
 
+// emitString emits a string literal with the type read before it, e.g. DATE '2024-01-01'.
+// It is kept apart from emit so that emit stays cheap enough to be inlined.
+func (s *Lexer) emitString(t TokenType) *Token {
+	tok := s.emit(t)
+	tok.literalType = s.literalType
+	s.literalType = ""
 	return tok
 }
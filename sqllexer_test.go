@@ -3,6 +3,8 @@ package sqllexer
 import (
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TokenSpec is a simplified token specification for testing
@@ -1170,6 +1172,158 @@ here */`,
 	}
 }
 
+func TestLexerNumbers(t *testing.T) {
+	tests := []struct {
+		input     string
+		dbms      DBMSType
+		tokenType TokenType
+		malformed bool
+	}{
+		{input: "42"},
+		{input: "-1.5e-3"},
+		{input: "-.5"},
+		{input: "1."},
+		{input: "0x1F"},
+		{input: "-0x1F"},
+		{input: "0b1010"},
+		{input: "0o17"},
+		{input: "0123"},
+		{input: "0128", malformed: true},
+		{input: "0128", dbms: DBMSPostgres},
+		{input: "1_000_000", dbms: DBMSPostgres},
+		{input: "1__000", dbms: DBMSPostgres, malformed: true},
+		{input: "1_000_", dbms: DBMSPostgres, malformed: true},
+		{input: "1_000", malformed: true},
+		{input: "1.2.3", malformed: true},
+		{input: "1e", malformed: true},
+		{input: "1e+", malformed: true},
+		{input: "0x", malformed: true},
+		{input: "0x", dbms: DBMSSQLServer},
+		{input: "0x1G", malformed: true},
+		{input: "0b12", malformed: true},
+		{input: "12abc", malformed: true},
+		{input: "12abc", dbms: DBMSMySQL, tokenType: IDENT},
+		{input: "1_000", dbms: DBMSMySQL, tokenType: IDENT},
+		{input: "0o17", dbms: DBMSMySQL, tokenType: IDENT},
+		{input: "1.2.3", dbms: DBMSMySQL, malformed: true},
+		{input: "x'ff'", dbms: DBMSMySQL},
+		{input: "X'0A1b'", dbms: DBMSMySQL},
+		{input: "b'0101'", dbms: DBMSMySQL},
+		{input: "x'f'", dbms: DBMSMySQL, malformed: true},
+		{input: "b'012'", dbms: DBMSMySQL, malformed: true},
+		{input: "x'ff", dbms: DBMSMySQL, malformed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dbms)+" "+tt.input, func(t *testing.T) {
+			tokenType := tt.tokenType
+			if tokenType == ERROR {
+				tokenType = NUMBER
+			}
+			lexer := New(tt.input, WithDBMS(tt.dbms))
+			token := lexer.Scan()
+			assert.Equal(t, tokenType, token.Type)
+			assert.Equal(t, tt.input, token.Value, "the literal is a single token")
+			assert.Equal(t, tt.malformed, token.IsMalformed())
+			assert.Equal(t, EOF, lexer.Scan().Type)
+		})
+	}
+}
+
+func TestLexerUnterminatedQuotedNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "SELECT x'ff FROM t WHERE a = 'secret'",
+			expected: []string{"SELECT", "x'ff", "FROM", "t", "WHERE", "a", "=", "'secret'"},
+		},
+		{
+			input:    "SELECT b'01 , password FROM t",
+			expected: []string{"SELECT", "b'01", ",", "password", "FROM", "t"},
+		},
+		{
+			input:    "SELECT x'fg' FROM t WHERE a = 'secret'",
+			expected: []string{"SELECT", "x'fg'", "FROM", "t", "WHERE", "a", "=", "'secret'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var values []string
+			for token := range New(tt.input, WithDBMS(DBMSMySQL)).ValueTokens() {
+				values = append(values, token.Value)
+			}
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestLexerTypedLiterals(t *testing.T) {
+	tests := []struct {
+		input       string
+		dbms        DBMSType
+		typeName    []string
+		literalType string
+	}{
+		{input: "DATE '2024-01-01'", typeName: []string{"DATE"}, literalType: "date"},
+		{input: "interval  '3 days'", typeName: []string{"interval"}, literalType: "interval"},
+		{input: "TIME '10:00'", typeName: []string{"TIME"}, literalType: "time"},
+		{input: "timestamptz '2024-01-01 00:00:00+00'", typeName: []string{"timestamptz"}, literalType: "timestamptz"},
+		{
+			input:       "TIMESTAMP With Time Zone '2024-01-01 00:00:00+00'",
+			typeName:    []string{"TIMESTAMP", "With", "Time", "Zone"},
+			literalType: "timestamp with time zone",
+		},
+		{
+			input:       "time without time zone '10:00'",
+			typeName:    []string{"time", "without", "time", "zone"},
+			literalType: "time without time zone",
+		},
+		{input: "DATE \"2024-01-01\"", dbms: DBMSMySQL, typeName: []string{"DATE"}, literalType: "date"},
+		{input: "'{}'::jsonb", literalType: "jsonb"},
+		{input: "'{1,2}'::INT[]", literalType: "int[]"},
+		{input: "DATE '2024-01-01'::text", typeName: []string{"DATE"}, literalType: "date"},
+		{input: "'bob'"},
+		{input: "at time zone 'UTC'"},
+		{input: "date = '2024-01-01'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var typeName []string
+			var literal Token
+			for token := range New(tt.input, WithDBMS(tt.dbms), WithTypedLiterals(true)).All() {
+				switch token.Type {
+				case KEYWORD:
+					typeName = append(typeName, token.Value)
+				case STRING:
+					literal = token
+				case IDENT:
+					if literal.Type != STRING {
+						assert.Empty(t, tt.typeName, "the type name is a keyword")
+					}
+				}
+			}
+			assert.Equal(t, tt.typeName, typeName)
+			assert.Equal(t, tt.literalType, literal.LiteralType())
+		})
+	}
+}
+
+func TestLexerTypedLiteralsDisabled(t *testing.T) {
+	// typed literals are only annotated WithTypedLiterals
+	var types []TokenType
+	var literalType string
+	for token := range New("SELECT DATE '2024-01-01', '{}'::jsonb").ValueTokens() {
+		types = append(types, token.Type)
+		literalType += token.LiteralType()
+	}
+	assert.Equal(t, []TokenType{COMMAND, IDENT, STRING, PUNCTUATION, STRING, OPERATOR, IDENT}, types)
+	assert.Empty(t, literalType)
+}
+
 func TestLexerIdentifierWithDigits(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -1482,6 +1636,89 @@ func TestLexerUnicode(t *testing.T) {
 	}
 }
 
+func TestLexerIterators(t *testing.T) {
+	query := "SELECT /* c */ id, name FROM users -- trailing\nWHERE id = 1"
+
+	t.Run("All", func(t *testing.T) {
+		var got []TokenSpec
+		for token := range New(query).All() {
+			got = append(got, TokenSpec{token.Type, token.Value})
+		}
+		want := []TokenSpec{
+			{COMMAND, "SELECT"},
+			{SPACE, " "},
+			{MULTILINE_COMMENT, "/* c */"},
+			{SPACE, " "},
+			{IDENT, "id"},
+			{PUNCTUATION, ","},
+			{SPACE, " "},
+			{IDENT, "name"},
+			{SPACE, " "},
+			{KEYWORD, "FROM"},
+			{SPACE, " "},
+			{IDENT, "users"},
+			{SPACE, " "},
+			{COMMENT, "-- trailing"},
+			{SPACE, "\n"},
+			{KEYWORD, "WHERE"},
+			{SPACE, " "},
+			{IDENT, "id"},
+			{SPACE, " "},
+			{OPERATOR, "="},
+			{SPACE, " "},
+			{NUMBER, "1"},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("ValueTokens", func(t *testing.T) {
+		var got []TokenSpec
+		for token := range New(query).ValueTokens() {
+			got = append(got, TokenSpec{token.Type, token.Value})
+		}
+		want := []TokenSpec{
+			{COMMAND, "SELECT"},
+			{IDENT, "id"},
+			{PUNCTUATION, ","},
+			{IDENT, "name"},
+			{KEYWORD, "FROM"},
+			{IDENT, "users"},
+			{KEYWORD, "WHERE"},
+			{IDENT, "id"},
+			{OPERATOR, "="},
+			{NUMBER, "1"},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Tokens are copies", func(t *testing.T) {
+		tokens := New("SELECT 1").Tokens()
+		assert.Len(t, tokens, 3)
+		// tokens must not alias the lexer's internal token
+		assert.Equal(t, COMMAND, tokens[0].Type)
+		assert.Equal(t, "SELECT", tokens[0].Value)
+		assert.Equal(t, NUMBER, tokens[2].Type)
+		assert.Equal(t, "1", tokens[2].Value)
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		lexer := New("SELECT 1 FROM t")
+		for token := range lexer.All() {
+			if token.Type == NUMBER {
+				break
+			}
+		}
+		// the lexer resumes after the last yielded token
+		rest := lexer.Tokens()
+		assert.Equal(t, " ", rest[0].Value)
+		assert.Equal(t, "t", rest[len(rest)-1].Value)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		assert.Empty(t, New("").Tokens())
+	})
+}
+
 func ExampleLexer() {
 	query := "SELECT * FROM users WHERE id = 1"
 	lexer := New(query)
@@ -1495,3 +1732,19 @@ func ExampleLexer() {
 		fmt.Println(token)
 	}
 }
+
+func ExampleLexer_ValueTokens() {
+	lexer := New("SELECT * FROM users WHERE id = 1")
+	for token := range lexer.ValueTokens() {
+		fmt.Println(token.Value)
+	}
+	// Output:
+	// SELECT
+	// *
+	// FROM
+	// users
+	// WHERE
+	// id
+	// =
+	// 1
+}
@@ -371,6 +371,42 @@ func isDigit(ch rune) bool {
 	return ch >= '0' && ch <= '9'
 }
 
+// isHexDigit checks if a rune is a hex digit (0-9, a-f, A-F)
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
+
+// isBinaryDigit checks if a rune is a binary digit (0 or 1)
+func isBinaryDigit(ch rune) bool {
+	return ch == '0' || ch == '1'
+}
+
+// isOctalDigit checks if a rune is an octal digit (0-7)
+func isOctalDigit(ch rune) bool {
+	return ch >= '0' && ch <= '7'
+}
+
+// numberRadix returns the radix of a number: 'x' for 0x1F and x'1f', 'b' for 0b101 and b'101',
+// 'o' for 0o17 and 0 for a decimal number, along with the index of its first digit
+func numberRadix(value string) (radix byte, start int) {
+	if len(value) > 0 && isLeadingSign(rune(value[0])) {
+		start = 1
+	}
+	if len(value) < start+2 {
+		return 0, start
+	}
+	prefix := value[start : start+2]
+	switch {
+	case prefix[0] == '0' && (prefix[1] == 'x' || prefix[1] == 'X'), (prefix[0] == 'x' || prefix[0] == 'X') && prefix[1] == '\'':
+		return 'x', start + 2
+	case prefix[0] == '0' && (prefix[1] == 'b' || prefix[1] == 'B'), (prefix[0] == 'b' || prefix[0] == 'B') && prefix[1] == '\'':
+		return 'b', start + 2
+	case prefix[0] == '0' && (prefix[1] == 'o' || prefix[1] == 'O'):
+		return 'o', start + 2
+	}
+	return 0, start
+}
+
 // isLeadingDigit checks if a rune is + or -
 func isLeadingSign(ch rune) bool {
 	return ch == '+' || ch == '-'
@@ -456,3 +492,22 @@ func isIdentifier(ch rune) bool {
 func isValueToken(token *Token) bool {
 	return token.Type != EOF && token.Type != SPACE && token.Type != COMMENT && token.Type != MULTILINE_COMMENT
 }
+
+// typedLiteralType returns the lowercased type of a typed string literal prefix such as DATE or INTERVAL, or ""
+func typedLiteralType(word string) string {
+	if len(word) < len("date") || len(word) > len("timestamptz") {
+		return ""
+	}
+	switch word[0] | 0x20 {
+	case 'd', 't', 'i':
+	default:
+		// most words, checked first as this is called for every keyword and identifier
+		return ""
+	}
+	for _, literalType := range []string{"date", "time", "timestamp", "timestamptz", "datetime", "interval"} {
+		if strings.EqualFold(word, literalType) {
+			return literalType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package sqllexer
+
+import "strings"
+
+// tupleFrame is an open parenthesis or bracket seen by the tuple context
+type tupleFrame struct {
+	// start is the offset of the tuple in its builder, including its ROW or ARRAY constructor
+	start int
+	// element is true when the tuple is an element of a list of tuples
+	element bool
+	// captured is true when the tuple is written to its own builder until it is known to be repeated
+	captured bool
+	// last is the normalized text of the last tuple of the list directly inside the frame, see tupleKey
+	last string
+	// position is the number of the last placeholder before the captured tuple
+	position int
+}
+
+// tupleContext collapses the repeated tuples of a list into the first one, see WithCollapseRepeatedTuples:
+//   - the rows of a multi-row VALUES, e.g. VALUES (?), (?), (?) is normalized as VALUES (?)
+//   - the tuples of an IN list, e.g. IN ((?), (?))
+//   - ROW and ARRAY constructors, e.g. ARRAY[ROW(?), ROW(?)] or ARRAY[[?], [?]]
+//
+// The first tuple is written as it is and the following ones are captured in their own builder,
+// then dropped when they are normalized the same as the previous one, whatever the numbers of their parameters.
+type tupleContext struct {
+	// frames holds the open parentheses and brackets, frames[0] is the statement
+	frames []tupleFrame
+	// builders holds the builders of the captured tuples. The normalized SQL builder is passed to each call
+	// rather than held, so that it does not escape to the heap.
+	builders []*strings.Builder
+	// closed is true right after a tuple of a list is closed
+	closed bool
+	// comma is true when the comma following a tuple is held until the next token tells whether a tuple follows
+	comma bool
+	// capture is true when the next bracket starts a captured tuple
+	capture bool
+	// constructor is the offset of the ROW or ARRAY constructor of the next tuple, -1 if none
+	constructor int
+	lexerOpts   []lexerOption
+}
+
+func newTupleContext(lexerOpts ...lexerOption) tupleContext {
+	return tupleContext{
+		frames:      []tupleFrame{{}},
+		constructor: -1,
+		lexerOpts:   lexerOpts,
+	}
+}
+
+// builder returns the builder the next token is written to, the normalized SQL builder when no tuple is captured
+func (c *tupleContext) builder(normalized *strings.Builder) *strings.Builder {
+	if len(c.builders) == 0 {
+		return normalized
+	}
+	return c.builders[len(c.builders)-1]
+}
+
+// open is called before the token is normalized. It returns the builder the token is written to,
+// and whether the token is skipped, that is the comma following a tuple.
+// placeholders numbers the parameters of the tuples.
+func (c *tupleContext) open(token *Token, lastValueToken *LastValueToken, normalized *strings.Builder, placeholders *placeholderContext) (*strings.Builder, bool) {
+	if !isValueToken(token) && token.Type != EOF {
+		return c.builder(normalized), false
+	}
+	closed := c.closed
+	c.closed = false
+	if token.Type == EOF {
+		c.flush(normalized)
+		return normalized, false
+	}
+	if token.Type == PUNCTUATION && token.Value == "," && closed && !c.comma {
+		c.comma = true
+		return c.builder(normalized), true
+	}
+
+	if c.comma {
+		c.comma = false
+		if isTupleStart(token) || isTupleConstructor(token) {
+			// capture the tuple with its comma until it is closed
+			capture := &strings.Builder{}
+			capture.WriteString(",")
+			c.builders = append(c.builders, capture)
+			c.capture = true
+		} else {
+			c.builder(normalized).WriteString(",")
+		}
+	}
+
+	builder := c.builder(normalized)
+	switch {
+	case isTupleStart(token):
+		frame := tupleFrame{
+			start:    builder.Len(),
+			element:  c.capture || c.constructor >= 0 || isTupleListStart(lastValueToken),
+			captured: c.capture,
+			position: placeholders.position,
+		}
+		if c.constructor >= 0 {
+			frame.start = c.constructor
+		}
+		c.frames = append(c.frames, frame)
+		c.capture = false
+		c.constructor = -1
+	case isTupleConstructor(token) && (c.capture || isTupleListStart(lastValueToken)):
+		c.constructor = builder.Len()
+	default:
+		c.constructor = -1
+		if c.capture {
+			// not a tuple after all, e.g. ROW used as a column name
+			c.capture = false
+			c.flushCapture(normalized)
+		}
+	}
+	return c.builder(normalized), false
+}
+
+// close is called after the token is normalized, and drops the tuple it closes when it is repeated.
+// The numbers given by placeholders in a dropped tuple are given again.
+func (c *tupleContext) close(token *Token, normalized *strings.Builder, placeholders *placeholderContext) {
+	if token.Type != PUNCTUATION || (token.Value != ")" && token.Value != "]") || len(c.frames) == 1 {
+		return
+	}
+	frame := c.frames[len(c.frames)-1]
+	c.frames = c.frames[:len(c.frames)-1]
+	if !frame.element {
+		return
+	}
+	parent := &c.frames[len(c.frames)-1]
+	c.closed = true
+	if !frame.captured {
+		parent.last = tupleKey(strings.TrimLeft(c.builder(normalized).String()[frame.start:], " "), c.lexerOpts...)
+		return
+	}
+	capture := c.builder(normalized)
+	c.builders = c.builders[:len(c.builders)-1]
+	tuple := tupleKey(strings.TrimLeft(capture.String(), ", "), c.lexerOpts...)
+	if tuple == parent.last {
+		// a repeated tuple
+		placeholders.reset(frame.position)
+		return
+	}
+	c.builder(normalized).WriteString(capture.String())
+	parent.last = tuple
+}
+
+// flush writes the held comma and the captured tuples left open at the end of the query
+func (c *tupleContext) flush(normalized *strings.Builder) {
+	if c.comma {
+		c.comma = false
+		c.builder(normalized).WriteString(",")
+	}
+	for len(c.builders) > 0 {
+		c.flushCapture(normalized)
+	}
+}
+
+// flushCapture writes the last captured tuple to the builder it was captured from
+func (c *tupleContext) flushCapture(normalized *strings.Builder) {
+	capture := c.builders[len(c.builders)-1]
+	c.builders = c.builders[:len(c.builders)-1]
+	c.builder(normalized).WriteString(capture.String())
+}
+
+// tupleKey returns the normalized text of the tuple without the numbers of its parameters,
+// so that ( $1, $2 ) and ( $3, $4 ) are the same tuple
+func tupleKey(tuple string, lexerOpts ...lexerOption) string {
+	if !strings.ContainsAny(tuple, "$:@") {
+		return tuple
+	}
+	var key strings.Builder
+	key.Grow(len(tuple))
+	for token := range New(tuple, lexerOpts...).All() {
+		if existingPlaceholderNumber(&token) > 0 {
+			key.WriteString(strings.TrimRight(token.Value, "0123456789"))
+		} else {
+			key.WriteString(token.Value)
+		}
+	}
+	return key.String()
+}
+
+func isTupleStart(token *Token) bool {
+	return token.Type == PUNCTUATION && (token.Value == "(" || token.Value == "[")
+}
+
+// isTupleConstructor reports whether the token is a ROW or ARRAY constructor
+func isTupleConstructor(token *Token) bool {
+	switch token.Type {
+	case IDENT, KEYWORD, FUNCTION:
+		return strings.EqualFold(token.Value, "ROW") || strings.EqualFold(token.Value, "ARRAY")
+	}
+	return false
+}
+
+// isTupleListStart reports whether a tuple following the token is the first of a list of tuples
+func isTupleListStart(lastValueToken *LastValueToken) bool {
+	if lastValueToken == nil {
+		return false
+	}
+	if lastValueToken.Type == PUNCTUATION {
+		return lastValueToken.Value == "(" || lastValueToken.Value == "["
+	}
+	return strings.EqualFold(lastValueToken.Value, "VALUES") || strings.EqualFold(lastValueToken.Value, "VALUE")
+}